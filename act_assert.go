@@ -2,9 +2,16 @@ package act_assert
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"testing"
 
+	"github.com/joho/godotenv"
 	"github.com/wd-hopkins/act/pkg/artifacts"
 	"github.com/wd-hopkins/act/pkg/common"
 
@@ -17,11 +24,29 @@ import (
 
 type ActAssert struct {
 	config
-	jobName              string
-	workflowFilePath     string
-	plan                 *model.Plan
-	runContexts          []*runner.RunContext
-	artifactServerConfig ArtifactServerConfig
+	jobName               string
+	workflowFilePath      string
+	plan                  *model.Plan
+	runContexts           []*runner.RunContext
+	artifactServerConfig  ArtifactServerConfig
+	eventPayload          EventPayload
+	actionMocks           []actionMock
+	reusableWorkflowMocks []reusableWorkflowMock
+	artifactSeeds         []artifactSeed
+	cacheServerConfig     *CacheServerConfig
+	cacheSeeds            []cacheSeed
+	cache                 *cacheServer
+	gitHubAPI             *GitHubAPI
+	events                *eventRecorder
+	captureStepSummaries  bool
+	commandCalls          []commandCall
+	stepSummaries         *stepSummaries
+	matrixOverrides       []*matrixOverride
+	stepFailures          []stepFailure
+	// workflows are the parsed workflow files of the plan, by path
+	workflows map[string]*rawWorkflow
+	// buildErrs are the errors of the builders, returned by Plan
+	buildErrs []error
 }
 
 func New() *ActAssert {
@@ -61,6 +86,30 @@ func (a *ActAssert) WithEvent(event string) *ActAssert {
 	return a
 }
 
+// WithEventPayload sets the event that triggers the workflow along with its webhook payload, which is
+// available to the workflow as `github.event`. The ref related GitHub environment variables, such as
+// GITHUB_REF and GITHUB_HEAD_REF, are derived from the payload and the default branch when the workflow is
+// executed, so that they are consistent with it. Variables set with WithEnvironment take precedence.
+func (a *ActAssert) WithEventPayload(payload EventPayload) *ActAssert {
+	a.eventName = payload.EventName()
+	a.eventPayload = payload
+	if dispatch, ok := payload.(WorkflowDispatchEvent); ok {
+		inputs := make(map[string]string, len(dispatch.Inputs))
+		for k, v := range dispatch.Inputs {
+			inputs[k] = fmt.Sprint(v)
+		}
+		a.WithInputs(inputs)
+	}
+	return a
+}
+
+// WithEventPath sets the path to a JSON file used as the event payload. Ignored if an event payload is set
+// with WithEventPayload.
+func (a *ActAssert) WithEventPath(path string) *ActAssert {
+	a.eventPath = path
+	return a
+}
+
 func (a *ActAssert) WithJobName(name string) *ActAssert {
 	a.jobName = name
 	return a
@@ -84,6 +133,59 @@ func (a *ActAssert) WithEnvironment(env map[GithubEnv]string) *ActAssert {
 	return a
 }
 
+// WithSecrets sets the values of the `secrets` context. Secret values are masked by the runner in job and step
+// logs.
+func (a *ActAssert) WithSecrets(secrets map[string]string) *ActAssert {
+	if a.secrets == nil {
+		a.secrets = make(map[string]string)
+	}
+	maps.Copy(a.secrets, secrets)
+	return a
+}
+
+// WithSecretsFile loads secrets from a dotenv formatted file, such as a `.secrets` file used by act. An error
+// reading the file is returned by Plan.
+func (a *ActAssert) WithSecretsFile(path string) *ActAssert {
+	secrets, err := readEnvFile(path)
+	if err != nil {
+		a.buildErrs = append(a.buildErrs, fmt.Errorf("secrets file: %w", err))
+		return a
+	}
+	return a.WithSecrets(secrets)
+}
+
+// WithSecretsFromEnv loads secrets from the process environment. Only variables starting with prefix are
+// loaded and the prefix is stripped from their names, e.g. with prefix "SECRET_", SECRET_TOKEN becomes TOKEN.
+func (a *ActAssert) WithSecretsFromEnv(prefix string) *ActAssert {
+	return a.WithSecrets(readPrefixedEnv(prefix))
+}
+
+// WithVars sets the values of the `vars` context.
+func (a *ActAssert) WithVars(vars map[string]string) *ActAssert {
+	if a.vars == nil {
+		a.vars = make(map[string]string)
+	}
+	maps.Copy(a.vars, vars)
+	return a
+}
+
+// WithVarsFile loads variables from a dotenv formatted file, such as a `.vars` file used by act. An error
+// reading the file is returned by Plan.
+func (a *ActAssert) WithVarsFile(path string) *ActAssert {
+	vars, err := readEnvFile(path)
+	if err != nil {
+		a.buildErrs = append(a.buildErrs, fmt.Errorf("vars file: %w", err))
+		return a
+	}
+	return a.WithVars(vars)
+}
+
+// WithVarsFromEnv loads variables from the process environment. Only variables starting with prefix are
+// loaded and the prefix is stripped from their names.
+func (a *ActAssert) WithVarsFromEnv(prefix string) *ActAssert {
+	return a.WithVars(readPrefixedEnv(prefix))
+}
+
 func (a *ActAssert) WithPlatform(label, image string) *ActAssert {
 	a.platforms[label] = image
 	return a
@@ -120,6 +222,10 @@ func (a *ActAssert) WithForcePull(pull bool) *ActAssert {
 }
 
 func (a *ActAssert) ConfigureArtifactServer(config ArtifactServerConfig) *ActAssert {
+	if config.T != nil {
+		config = config.withTestDefaults()
+	}
+	a.artifactServerConfig = config
 	a.artifactServerPath = config.Path
 	if config.Port > 0 {
 		a.artifactServerPort = strconv.Itoa(config.Port)
@@ -131,6 +237,9 @@ func (a *ActAssert) ConfigureArtifactServer(config ArtifactServerConfig) *ActAss
 }
 
 func (a *ActAssert) Plan() (*ActAssert, error) {
+	if err := errors.Join(a.buildErrs...); err != nil {
+		return a, err
+	}
 	planner, err := model.NewWorkflowPlanner(a.workflowFilePath, true, false)
 	if err != nil {
 		return a, err
@@ -143,50 +252,139 @@ func (a *ActAssert) Plan() (*ActAssert, error) {
 	} else {
 		a.plan, err = planner.PlanAll()
 	}
+	if err != nil {
+		return a, err
+	}
 
+	a.workflows, err = a.readWorkflows(a.plan)
 	return a, err
 }
 
+var errNoPlan = errors.New("plan is nil. Did you forget to call Plan()?")
+
+// Job returns the plan of the job with the given ID. Panics if the job is not in the plan.
 func (a *ActAssert) Job(name string) *JobPlan {
+	job, err := a.LookupJob(name)
+	if err != nil {
+		panic(err)
+	}
+	return job
+}
+
+// LookupJob returns the plan of the job with the given ID, or an error if the job is not in the plan.
+func (a *ActAssert) LookupJob(name string) (*JobPlan, error) {
 	if a.plan == nil {
-		panic("Plan is nil. Did you forget to call Plan()?")
+		return nil, errNoPlan
 	}
 
 	var job *model.Run
+	var jobIDs []string
 	for _, stage := range a.plan.Stages {
 		for _, run := range stage.Runs {
 			if run.JobID == name {
 				job = run
 			}
+			jobIDs = append(jobIDs, run.JobID)
 		}
 	}
 
 	if job == nil {
-		panic("Job not found in plan")
+		return nil, fmt.Errorf("job '%s' not found in plan, available jobs: %s", name, strings.Join(jobIDs, ", "))
 	}
+	return a.newJobPlan(job), nil
+}
+
+// RequireJob returns the plan of the job with the given ID. Fails the test if the job is not in the plan.
+func (a *ActAssert) RequireJob(t testing.TB, name string) *JobPlan {
+	t.Helper()
+	job, err := a.LookupJob(name)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return job
+}
+
+func (a *ActAssert) newJobPlan(run *model.Run) *JobPlan {
 	return &JobPlan{
-		jobRun:      job,
+		JobNode:     a.newJobNode(run),
+		name:        run.JobID,
+		jobRun:      run,
 		stepOutputs: make(map[string]map[string]string),
+		act:         a,
+	}
+}
+
+// Stages returns the jobs in the plan, grouped by the stage they run in. The jobs of a stage only depend on
+// jobs of earlier stages. Panics if Plan has not been called.
+func (a *ActAssert) Stages() [][]*JobNode {
+	stages, err := a.LookupStages()
+	if err != nil {
+		panic(err)
+	}
+	return stages
+}
+
+// LookupStages returns the jobs in the plan, grouped by the stage they run in, or an error if Plan has not
+// been called.
+func (a *ActAssert) LookupStages() ([][]*JobNode, error) {
+	if a.plan == nil {
+		return nil, errNoPlan
 	}
+	var stages [][]*JobNode
+	for _, stage := range a.plan.Stages {
+		var jobs []*JobNode
+		for _, run := range stage.Runs {
+			jobs = append(jobs, a.newJobNode(run))
+		}
+		stages = append(stages, jobs)
+	}
+	return stages, nil
 }
 
+// RequireStages returns the jobs in the plan, grouped by the stage they run in. Fails the test if Plan has not
+// been called.
+func (a *ActAssert) RequireStages(t testing.TB) [][]*JobNode {
+	t.Helper()
+	stages, err := a.LookupStages()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return stages
+}
+
+// AllJobs returns the plans of all jobs in the plan. Panics if Plan has not been called.
 func (a *ActAssert) AllJobs() []*JobPlan {
+	jobs, err := a.LookupAllJobs()
+	if err != nil {
+		panic(err)
+	}
+	return jobs
+}
+
+// LookupAllJobs returns the plans of all jobs in the plan, or an error if Plan has not been called.
+func (a *ActAssert) LookupAllJobs() ([]*JobPlan, error) {
 	if a.plan == nil {
-		panic("Plan is nil. Did you forget to call Plan()?")
+		return nil, errNoPlan
 	}
 
 	var jobs []*JobPlan
 
 	for _, stage := range a.plan.Stages {
 		for _, run := range stage.Runs {
-			jobs = append(jobs, &JobPlan{
-				name:        run.JobID,
-				jobRun:      run,
-				stepOutputs: make(map[string]map[string]string),
-			})
+			jobs = append(jobs, a.newJobPlan(run))
 		}
 	}
 
+	return jobs, nil
+}
+
+// RequireAllJobs returns the plans of all jobs in the plan. Fails the test if Plan has not been called.
+func (a *ActAssert) RequireAllJobs(t testing.TB) []*JobPlan {
+	t.Helper()
+	jobs, err := a.LookupAllJobs()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
 	return jobs
 }
 
@@ -201,46 +399,192 @@ func (a *ActAssert) SetJobResultsFunc(result Result, f func(*model.Job) bool) *A
 	return a
 }
 
+// Execute runs the planned workflow. See ExecuteContext.
 func (a *ActAssert) Execute() error {
+	return a.ExecuteContext(context.Background())
+}
+
+// ExecuteContext runs the planned workflow until it completes or ctx is done. When ctx can be cancelled,
+// job containers are removed on completion, so that containers of cancelled jobs are torn down.
+// The returned error is an *ExecutionError distinguishing infrastructure errors from failing jobs.
+// Results are available even if an error is returned.
+func (a *ActAssert) ExecuteContext(ctx context.Context) error {
+	if a.plan == nil {
+		return infrastructureError(errNoPlan)
+	}
 	socket, err := container.GetSocketAndHost("docker")
 	if err != nil {
-		return err
+		return infrastructureError(err)
 	}
 	a.containerDaemonSocket = socket.Socket
-	r, err := runner.New(a.config.toRunnerConfig())
+	runnerConfig := a.runnerConfig()
+	if ctx.Done() != nil {
+		// act only removes the containers of successful jobs unless AutoRemove is set
+		runnerConfig.AutoRemove = true
+	}
+	if a.eventPayload != nil {
+		eventPath, err := writeEventPayload(a.eventPayload)
+		if err != nil {
+			return infrastructureError(err)
+		}
+		defer os.Remove(eventPath)
+		runnerConfig.EventPath = eventPath
+	}
+	cacheURL, stopCacheServer, err := a.startCacheServer()
 	if err != nil {
-		return err
+		return infrastructureError(err)
+	}
+	defer stopCacheServer()
+	if cacheURL != "" {
+		runnerConfig.Env = maps.Clone(runnerConfig.Env)
+		if runnerConfig.Env == nil {
+			runnerConfig.Env = map[string]string{}
+		}
+		runnerConfig.Env["ACTIONS_CACHE_URL"] = cacheURL
+		if _, ok := runnerConfig.Env["ACTIONS_RUNTIME_TOKEN"]; !ok && a.artifactServerPath == "" {
+			// actions/cache requires a token, which act only provides along with the artifact server
+			runnerConfig.Env["ACTIONS_RUNTIME_TOKEN"] = "token"
+		}
+	}
+	if a.gitHubAPI != nil {
+		if err := a.gitHubAPI.start(a.artifactServerAddr); err != nil {
+			return infrastructureError(fmt.Errorf("GitHub API: %w", err))
+		}
+		defer a.gitHubAPI.stop()
+		runnerConfig.Env = maps.Clone(runnerConfig.Env)
+		if runnerConfig.Env == nil {
+			runnerConfig.Env = map[string]string{}
+		}
+		for k, v := range a.gitHubAPI.env() {
+			runnerConfig.Env[string(k)] = v
+		}
+	}
+	r, err := runner.New(runnerConfig)
+	if err != nil {
+		return infrastructureError(err)
 	}
-	ctx := context.Background()
 
 	// Start artifact server if configured
 	serverAddr := a.artifactServerAddr
 	if serverAddr == "host.docker.internal" {
 		serverAddr = "localhost"
 	}
+	if a.artifactServerPath != "" {
+		if err := checkPortAvailable(serverAddr, a.artifactServerPort); err != nil {
+			return infrastructureError(fmt.Errorf("artifact server: %w", err))
+		}
+	}
+	if err := a.seedArtifacts(); err != nil {
+		return infrastructureError(err)
+	}
 	cancel := artifacts.Serve(ctx, a.artifactServerPath, serverAddr, a.artifactServerPort)
 	defer func(cancel context.CancelFunc, path string) {
 		cancel()
-		if a.artifactServerConfig.Cleanup {
+		if a.artifactServerConfig.Cleanup && a.artifactServerConfig.T == nil {
 			_ = os.RemoveAll(path)
 		}
 	}(cancel, a.artifactServerPath)
 
+	// Record the job logs, which carry the workflow commands emitted by steps
+	a.events = newEventRecorder()
+	ctx = common.WithLoggerHook(ctx, a.events)
+
+	finishStepSummaries, err := a.applyStepSummaries()
+	if err != nil {
+		return infrastructureError(err)
+	}
+	defer finishStepSummaries()
+	finishCommandStubs, err := a.applyCommandStubs()
+	if err != nil {
+		return infrastructureError(err)
+	}
+	defer finishCommandStubs()
+	restoreActionMocks := a.applyActionMocks()
+	defer restoreActionMocks()
+	restoreStepFailures := a.applyStepFailures(a.plan)
+	defer restoreStepFailures()
+	forced := forcedJobResults(a.plan, a.matrixOverrides)
+	finishMatrixOverrides := a.applyMatrixOverrides(a.plan)
+	defer finishMatrixOverrides()
+	finishCancellations := a.applyCancellations(a.plan, a.events)
+	defer finishCancellations()
+	// After applyCancellations, which finds cancelled stubs by the uses of the calling jobs
+	restoreWorkflowMocks, err := a.applyReusableWorkflowMocks()
+	if err != nil {
+		return infrastructureError(err)
+	}
+	defer restoreWorkflowMocks()
+
 	e := r.NewPlanExecutor(a.plan)
 	err = e(ctx)
 	if err != nil {
 		common.Logger(ctx).Errorf("Error executing plan: %v", err)
 	}
 	a.runContexts = r.GetRunContexts()
-	return nil
+	applyFailFast(a.runContexts, a.events)
+	if ctx.Err() != nil {
+		return &ExecutionError{Kind: CancelledError, Err: errors.Join(ctx.Err(), err)}
+	}
+	return classifyPlanError(err, a.runContexts, forced)
+}
+
+// runnerConfig returns the configuration of the runner, with the ref related GitHub environment variables
+// derived from the event payload.
+func (a *ActAssert) runnerConfig() *runner.Config {
+	runnerConfig := a.config.toRunnerConfig()
+	if a.eventPayload == nil {
+		return runnerConfig
+	}
+	env := map[string]string{}
+	for k, v := range a.eventPayload.githubEnv(a.defaultBranch) {
+		env[string(k)] = v
+	}
+	maps.Copy(env, runnerConfig.Env)
+	runnerConfig.Env = env
+	return runnerConfig
+}
+
+func checkPortAvailable(addr, port string) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(addr, port))
+	if err != nil {
+		return err
+	}
+	return listener.Close()
+}
+
+func readEnvFile(path string) (map[string]string, error) {
+	return godotenv.Read(path)
+}
+
+func readPrefixedEnv(prefix string) map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		if name, ok := strings.CutPrefix(k, prefix); ok && name != "" {
+			env[name] = v
+		}
+	}
+	return env
 }
 
 func (a *ActAssert) Copy() *ActAssert {
 	// Create a new ActAssert with the same configuration, save for runContexts
 	return &ActAssert{
-		config:           a.config.Clone(),
-		jobName:          a.jobName,
-		workflowFilePath: a.workflowFilePath,
-		plan:             a.plan,
+		config:                a.config.Clone(),
+		jobName:               a.jobName,
+		workflowFilePath:      a.workflowFilePath,
+		plan:                  a.plan,
+		workflows:             a.workflows,
+		artifactServerConfig:  a.artifactServerConfig,
+		eventPayload:          a.eventPayload,
+		actionMocks:           slices.Clone(a.actionMocks),
+		reusableWorkflowMocks: slices.Clone(a.reusableWorkflowMocks),
+		artifactSeeds:         slices.Clone(a.artifactSeeds),
+		cacheServerConfig:     a.cacheServerConfig,
+		cacheSeeds:            slices.Clone(a.cacheSeeds),
+		gitHubAPI:             a.gitHubAPI,
+		captureStepSummaries:  a.captureStepSummaries,
+		matrixOverrides:       cloneMatrixOverrides(a.matrixOverrides),
+		stepFailures:          slices.Clone(a.stepFailures),
 	}
 }
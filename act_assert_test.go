@@ -0,0 +1,72 @@
+package act_assert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_with_secrets_and_vars(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/secrets.yaml").
+		WithSecrets(map[string]string{"API_TOKEN": "super-secret-value"}).
+		WithVars(map[string]string{"ENVIRONMENT": "production"}).
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("secrets_job")
+	assert.Contains(t, job.Masks(), "super-secret-value")
+	assert.NotContains(t, job.Logs(), "super-secret-value")
+	assert.Equal(t, "secret=***", job.Step("Print secret").Logs())
+	assert.Equal(t, "token=***", job.Step("Print token").Logs(), "secrets echoed by a step are masked")
+	assert.Equal(t, "var=production", job.Step("Print var").Logs())
+}
+
+func Test_with_secrets_and_vars_files(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/secrets.yaml").
+		WithSecretsFile("test/.secrets").
+		WithVarsFile("test/.vars").
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("secrets_job")
+	assert.Contains(t, job.Masks(), "file-secret-value")
+	assert.NotContains(t, job.Logs(), "file-secret-value")
+	assert.Equal(t, "secret=***", job.Step("Print secret").Logs())
+	assert.Equal(t, "var=staging", job.Step("Print var").Logs())
+}
+
+func Test_with_secrets_from_env(t *testing.T) {
+	t.Setenv("TEST_SECRET_API_TOKEN", "env-secret-value")
+
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/secrets.yaml").
+		WithSecretsFromEnv("TEST_SECRET_").
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("secrets_job")
+	assert.Contains(t, job.Masks(), "env-secret-value")
+	assert.NotContains(t, job.Logs(), "env-secret-value")
+	assert.Equal(t, "secret=***", job.Step("Print secret").Logs())
+}
+
+func Test_with_missing_secrets_file(t *testing.T) {
+	_, err := act_assert.New().
+		WithWorkflowPath("test/secrets.yaml").
+		WithSecretsFile("test/.missing-secrets").
+		WithVarsFile("test/.vars").
+		Plan()
+	assert.ErrorContains(t, err, "secrets file")
+}
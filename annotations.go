@@ -0,0 +1,144 @@
+package act_assert
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type AnnotationLevel string
+
+const (
+	AnnotationError   AnnotationLevel = "error"
+	AnnotationWarning AnnotationLevel = "warning"
+	AnnotationNotice  AnnotationLevel = "notice"
+	AnnotationDebug   AnnotationLevel = "debug"
+	// AnnotationGroup marks the start of a log group, the message being the title of the group.
+	AnnotationGroup AnnotationLevel = "group"
+)
+
+// Annotation is a workflow command emitted by a step, e.g. `::error file=app.js,line=1::Missing semicolon`.
+type Annotation struct {
+	Level     AnnotationLevel
+	Message   string
+	Title     string
+	File      string
+	Line      int
+	EndLine   int
+	Col       int
+	EndColumn int
+	// StepID is the ID of the step that emitted the annotation.
+	StepID string
+}
+
+type Annotations []Annotation
+
+// ByLevel returns the annotations with the given level.
+func (a Annotations) ByLevel(level AnnotationLevel) Annotations {
+	return a.filter(func(annotation Annotation) bool {
+		return annotation.Level == level
+	})
+}
+
+func (a Annotations) Errors() Annotations {
+	return a.ByLevel(AnnotationError)
+}
+
+func (a Annotations) Warnings() Annotations {
+	return a.ByLevel(AnnotationWarning)
+}
+
+func (a Annotations) Notices() Annotations {
+	return a.ByLevel(AnnotationNotice)
+}
+
+// ForFile returns the annotations referring to the given file.
+func (a Annotations) ForFile(file string) Annotations {
+	return a.filter(func(annotation Annotation) bool {
+		return annotation.File == file
+	})
+}
+
+// Messages returns the message of each annotation.
+func (a Annotations) Messages() []string {
+	var messages []string
+	for _, annotation := range a {
+		messages = append(messages, annotation.Message)
+	}
+	return messages
+}
+
+func (a Annotations) filter(f func(Annotation) bool) Annotations {
+	var filtered Annotations
+	for _, annotation := range a {
+		if f(annotation) {
+			filtered = append(filtered, annotation)
+		}
+	}
+	return filtered
+}
+
+var annotationLevels = []AnnotationLevel{AnnotationError, AnnotationWarning, AnnotationNotice, AnnotationDebug, AnnotationGroup}
+
+// workflowCommandRegex matches a workflow command at the start of a log line, optionally after the timestamp
+// the runner prefixes the line with. Lines merely mentioning a command are not workflow commands.
+var workflowCommandRegex = regexp.MustCompile(`^(?:\d{4}-\d{2}-\d{2}T[0-9:.]+Z )?::([a-z-]+)(?: ([^:]*))?::(.*)$`)
+
+// parseAnnotation parses a log line consisting of an annotation workflow command.
+func parseAnnotation(line string) (Annotation, bool) {
+	match := workflowCommandRegex.FindStringSubmatch(strings.TrimRight(line, "\r"))
+	if match == nil || !slices.Contains(annotationLevels, AnnotationLevel(match[1])) {
+		return Annotation{}, false
+	}
+	annotation := Annotation{
+		Level:   AnnotationLevel(match[1]),
+		Message: unescapeCommandData(match[3]),
+	}
+	for _, kv := range strings.Split(match[2], ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			continue
+		}
+		v = unescapeCommandProperty(v)
+		switch k {
+		case "title":
+			annotation.Title = v
+		case "file":
+			annotation.File = v
+		case "line":
+			annotation.Line, _ = strconv.Atoi(v)
+		case "endLine":
+			annotation.EndLine, _ = strconv.Atoi(v)
+		case "col":
+			annotation.Col, _ = strconv.Atoi(v)
+		case "endColumn":
+			annotation.EndColumn, _ = strconv.Atoi(v)
+		}
+	}
+	return annotation, true
+}
+
+func unescapeCommandData(s string) string {
+	return strings.NewReplacer("%0D", "\r", "%0A", "\n", "%25", "%").Replace(s)
+}
+
+func unescapeCommandProperty(s string) string {
+	return strings.NewReplacer("%0D", "\r", "%0A", "\n", "%3A", ":", "%2C", ",", "%25", "%").Replace(s)
+}
+
+// eventAnnotations returns the annotations contained in the events.
+func eventAnnotations(events []executionEvent) Annotations {
+	var annotations Annotations
+	for _, event := range events {
+		line, ok := event.data["raw"].(string)
+		if !ok {
+			line = event.message
+		}
+		if annotation, ok := parseAnnotation(line); ok {
+			annotation.StepID = event.stepID()
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
@@ -0,0 +1,65 @@
+package act_assert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_step_annotations(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/annotations.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	annotations := results.Job("lint").Step("Lint").Annotations()
+	assert.Equal(t, act_assert.Annotations{{
+		Level:   act_assert.AnnotationError,
+		Message: "Missing semicolon",
+		Title:   "Lint error",
+		File:    "src/app.js",
+		Line:    10,
+		Col:     5,
+		StepID:  "0",
+	}}, annotations.Errors())
+	assert.Equal(t, []string{"Unused variable\nx"}, annotations.Warnings().Messages())
+	assert.Equal(t, []string{"Linting sources"}, annotations.ByLevel(act_assert.AnnotationGroup).Messages())
+	assert.Empty(t, annotations.Notices())
+}
+
+func Test_job_annotations(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/annotations.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	annotations := results.Job("lint").Annotations()
+	assert.Len(t, annotations.Errors().ForFile("src/app.js"), 1)
+	assert.Len(t, annotations.Warnings(), 1)
+	if assert.Len(t, annotations.Notices(), 1) {
+		assert.Equal(t, "Summary", annotations.Notices()[0].Title)
+	}
+}
+
+func Test_annotations_at_line_start(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/annotations.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	// A line mentioning a command is not an annotation
+	results := act_assert.NewResults(*workflow)
+	docs := results.Job("lint").Step("Docs")
+	assert.Equal(t, "see ::error:: docs", docs.Logs())
+	assert.Empty(t, docs.Annotations())
+	assert.Len(t, results.Job("lint").Annotations().Errors(), 1)
+}
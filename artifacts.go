@@ -0,0 +1,313 @@
+package act_assert
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// gzipSuffix is the suffix of files of v3 artifacts uploaded gzip compressed.
+const gzipSuffix = ".gz__"
+
+// Artifact is an artifact stored by the artifact server.
+type Artifact struct {
+	Name string
+	// Size is the total uncompressed size of the files of the artifact in bytes.
+	Size int64
+	// Files are the paths of the files of the artifact, relative to the artifact root.
+	Files []string
+	// Job is the ID of the job that uploaded the artifact, if it was uploaded with actions/upload-artifact.
+	Job string
+
+	dir     string
+	zipPath string
+}
+
+// runID returns the workflow run ID the artifacts of the run are stored under.
+func (c config) runID() string {
+	if runID := c.env[string(GithubRunID)]; runID != "" {
+		return runID
+	}
+	return "1"
+}
+
+// Artifacts returns the artifacts stored by the artifact server for the workflow run, sorted by name.
+// Both the v3 and the v4 storage layouts are supported. Requires ActAssert.ConfigureArtifactServer.
+func (r *Results) Artifacts() ([]*Artifact, error) {
+	if r.artifactServerPath == "" {
+		return nil, fmt.Errorf("the artifact server is not configured. Did you forget to call ConfigureArtifactServer()?")
+	}
+	runDir := filepath.Join(r.artifactServerPath, r.runID)
+	entries, err := os.ReadDir(runDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []*Artifact
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		artifact, err := readArtifact(filepath.Join(runDir, entry.Name()), entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		artifact.Job = r.artifactUploader(artifact.Name)
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, nil
+}
+
+// Artifact returns the artifact with the given name. Panics if the artifact was not stored.
+func (r *Results) Artifact(name string) *Artifact {
+	artifact, err := r.LookupArtifact(name)
+	if err != nil {
+		panic(err)
+	}
+	return artifact
+}
+
+// LookupArtifact returns the artifact with the given name, or an error if the artifact was not stored.
+func (r *Results) LookupArtifact(name string) (*Artifact, error) {
+	artifacts, err := r.Artifacts()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, artifact := range artifacts {
+		if artifact.Name == name {
+			return artifact, nil
+		}
+		names = append(names, artifact.Name)
+	}
+	return nil, fmt.Errorf("artifact '%s' not found, available artifacts: %s", name, strings.Join(names, ", "))
+}
+
+// RequireArtifact returns the artifact with the given name. Fails the test if the artifact was not stored.
+func (r *Results) RequireArtifact(t testing.TB, name string) *Artifact {
+	t.Helper()
+	artifact, err := r.LookupArtifact(name)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return artifact
+}
+
+// artifactUploader returns the ID of the job with an actions/upload-artifact step uploading the artifact name.
+func (r *Results) artifactUploader(name string) string {
+	for _, job := range r.Jobs() {
+		for _, step := range job.runContext.Run.Job().Steps {
+			if !matchesUses("actions/upload-artifact@*", step.Uses) || step.EnvEvaluated == nil {
+				continue
+			}
+			uploaded := step.EnvEvaluated[inputEnvKey("name")]
+			if uploaded == "" {
+				uploaded = "artifact"
+			}
+			if uploaded == name {
+				return job.ID()
+			}
+		}
+	}
+	return ""
+}
+
+// readArtifact reads the artifact stored in dir. v4 artifacts are stored as a single zip archive named after
+// the artifact, v3 artifacts as the uploaded files, with gzip compressed files having the suffix `.gz__`.
+func readArtifact(dir, name string) (*Artifact, error) {
+	artifact := &Artifact{Name: name, dir: dir}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 1 && entries[0].Name() == name+".zip" && entries[0].Type().IsRegular() {
+		artifact.zipPath = filepath.Join(dir, entries[0].Name())
+		archive, err := zip.OpenReader(artifact.zipPath)
+		if err != nil {
+			return nil, err
+		}
+		defer archive.Close()
+		for _, file := range archive.File {
+			if file.FileInfo().IsDir() {
+				continue
+			}
+			artifact.Files = append(artifact.Files, file.Name)
+			artifact.Size += int64(file.UncompressedSize64)
+		}
+		slices.Sort(artifact.Files)
+		return artifact, nil
+	}
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = strings.TrimSuffix(filepath.ToSlash(rel), gzipSuffix)
+		content, err := artifact.read(rel)
+		if err != nil {
+			return err
+		}
+		artifact.Files = append(artifact.Files, rel)
+		artifact.Size += int64(len(content))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(artifact.Files)
+	return artifact, nil
+}
+
+// Open returns a reader over the uncompressed content of the file of the artifact at path.
+func (a *Artifact) Open(path string) (io.Reader, error) {
+	content, err := a.read(path)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(content), nil
+}
+
+func (a *Artifact) read(path string) ([]byte, error) {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	if a.zipPath != "" {
+		archive, err := zip.OpenReader(a.zipPath)
+		if err != nil {
+			return nil, err
+		}
+		defer archive.Close()
+		file, err := archive.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("artifact '%s': %w", a.Name, err)
+		}
+		defer file.Close()
+		return io.ReadAll(file)
+	}
+
+	filePath := filepath.Join(a.dir, filepath.FromSlash(path))
+	if !strings.HasPrefix(filePath, filepath.Clean(a.dir)+string(filepath.Separator)) {
+		return nil, fmt.Errorf("artifact '%s': invalid path '%s'", a.Name, path)
+	}
+	if content, err := os.ReadFile(filePath); err == nil || !os.IsNotExist(err) {
+		return content, err
+	}
+	file, err := os.Open(filePath + gzipSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("artifact '%s': %w", a.Name, err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+type artifactSeed struct {
+	name  string
+	files map[string][]byte
+	dir   string
+}
+
+// SeedArtifact stores an artifact with the given files, keyed by their path within the artifact, before the
+// workflow runs, so that jobs downloading it can be tested without the job uploading it. The artifact is
+// stored in the v4 layout. Requires ConfigureArtifactServer.
+func (a *ActAssert) SeedArtifact(name string, files map[string][]byte) *ActAssert {
+	a.artifactSeeds = append(a.artifactSeeds, artifactSeed{name: name, files: files})
+	return a
+}
+
+// SeedArtifactFromDir stores an artifact with the files in dir before the workflow runs, see SeedArtifact.
+func (a *ActAssert) SeedArtifactFromDir(name, dir string) *ActAssert {
+	a.artifactSeeds = append(a.artifactSeeds, artifactSeed{name: name, dir: dir})
+	return a
+}
+
+// seedArtifacts stores the seeded artifacts in the artifact server storage.
+func (a *ActAssert) seedArtifacts() error {
+	if len(a.artifactSeeds) == 0 {
+		return nil
+	}
+	if a.artifactServerPath == "" {
+		return fmt.Errorf("seeding artifacts requires the artifact server. Did you forget to call ConfigureArtifactServer()?")
+	}
+	for _, seed := range a.artifactSeeds {
+		files := seed.files
+		if seed.dir != "" {
+			var err error
+			if files, err = readDirFiles(seed.dir); err != nil {
+				return fmt.Errorf("seeding artifact '%s': %w", seed.name, err)
+			}
+		}
+		if err := writeArtifact(filepath.Join(a.artifactServerPath, a.runID(), seed.name), seed.name, files); err != nil {
+			return fmt.Errorf("seeding artifact '%s': %w", seed.name, err)
+		}
+	}
+	return nil
+}
+
+// writeArtifact writes files as a v4 artifact to dir, i.e. as a zip archive named after the artifact.
+func writeArtifact(dir, name string, files map[string][]byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(dir, name+".zip"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	archive := zip.NewWriter(file)
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		writer, err := archive.Create(strings.TrimPrefix(filepath.ToSlash(path), "/"))
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(files[path]); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// readDirFiles returns the content of the files in dir, keyed by their path relative to dir.
+func readDirFiles(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = content
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,60 @@
+package act_assert_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_read_v4_artifact(t *testing.T) {
+	workflow := act_assert.New().
+		ConfigureArtifactServer(act_assert.ArtifactServerConfig{Path: ".artifacts"})
+
+	results := act_assert.NewResults(*workflow)
+	artifacts, err := results.Artifacts()
+	assert.NoError(t, err)
+	assert.Len(t, artifacts, 1)
+	assert.Equal(t, "artifact", artifacts[0].Name)
+	assert.Equal(t, []string{"test-artifact.txt"}, artifacts[0].Files)
+	assert.Equal(t, int64(5), artifacts[0].Size)
+
+	reader, err := results.Artifact("artifact").Open("test-artifact.txt")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(reader)
+	assert.Equal(t, "Test\n", string(content))
+}
+
+func Test_read_v3_artifact(t *testing.T) {
+	dir := t.TempDir()
+	artifactDir := filepath.Join(dir, "42", "logs")
+	assert.NoError(t, os.MkdirAll(filepath.Join(artifactDir, "nested"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(artifactDir, "build.log"), []byte("build ok"), 0o644))
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write([]byte("tests ok"))
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, os.WriteFile(filepath.Join(artifactDir, "nested", "test.log.gz__"), compressed.Bytes(), 0o644))
+
+	workflow := act_assert.New().
+		WithEnvironment(map[act_assert.GithubEnv]string{act_assert.GithubRunID: "42"}).
+		ConfigureArtifactServer(act_assert.ArtifactServerConfig{Path: dir})
+
+	results := act_assert.NewResults(*workflow)
+	artifact := results.Artifact("logs")
+	assert.Equal(t, []string{"build.log", "nested/test.log"}, artifact.Files)
+	assert.Equal(t, int64(16), artifact.Size)
+
+	reader, err := artifact.Open("nested/test.log")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(reader)
+	assert.Equal(t, "tests ok", string(content))
+
+	_, err = results.LookupArtifact("missing")
+	assert.ErrorContains(t, err, "artifact 'missing' not found, available artifacts: logs")
+}
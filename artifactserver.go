@@ -1,12 +1,77 @@
 package act_assert
 
+import (
+	"net"
+	"os"
+	"strconv"
+	"testing"
+)
+
 type ArtifactServerConfig struct {
 	// Host Defines the address to which the artifact server binds.
 	Host string
-	// Port Defines the port where the artifact server listens.
+	// Port Defines the port where the artifact server listens. If not specified and T is set, a free port is used.
 	Port int
-	// Path Defines the path where the artifact server stores uploads and retrieves downloads from. If not specified the artifact server will not start.
+	// Path Defines the path where the artifact server stores uploads and retrieves downloads from. If not specified the artifact server will not start, unless T is set.
 	Path string
 	// Cleanup Indicates whether to clean up the artifact storage path after use.
 	Cleanup bool
+	// T Ties the artifact server to a test. If set, Path defaults to a temporary directory, Port to a free port,
+	// and the storage path is removed when the test completes rather than after each execution, so that
+	// artifacts can be inspected in the results.
+	T testing.TB
+}
+
+// withTestDefaults fills in the storage path and port of a config tied to a test and registers the cleanup of
+// the storage path with the test.
+func (c ArtifactServerConfig) withTestDefaults() ArtifactServerConfig {
+	t := c.T
+	t.Helper()
+	if c.Path == "" {
+		c.Path = t.TempDir()
+	}
+	if c.Port == 0 {
+		port, err := freePort()
+		if err != nil {
+			t.Fatalf("artifact server: %v", err)
+		}
+		c.Port = port
+	}
+	if c.Cleanup {
+		path := c.Path
+		t.Cleanup(func() {
+			_ = os.RemoveAll(path)
+		})
+	}
+	return c
+}
+
+// listen listens on a free port for connections from job containers, which reach the host at addr, and
+// returns the listener and its URL.
+func listen(addr string) (net.Listener, string, error) {
+	listenAddr := addr
+	if listenAddr == "host.docker.internal" {
+		// Only resolvable from within containers
+		listenAddr = ""
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(listenAddr, "0"))
+	if err != nil {
+		return nil, "", err
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return listener, "http://" + net.JoinHostPort(addr, port), nil
+}
+
+// freePort returns a TCP port that is currently free on every interface.
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(port)
 }
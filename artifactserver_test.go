@@ -3,6 +3,8 @@ package act_assert_test
 import (
 	"github.com/stretchr/testify/assert"
 	act_assert "github.com/wd-hopkins/act-assert"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -25,4 +27,54 @@ func Test_artifact_server(t *testing.T) {
 	downloadJob := results.Job("download")
 	assert.Equal(t, act_assert.Success, downloadJob.Result())
 	assert.Equal(t, "test-artifact.txt", downloadJob.Step("List files").Logs())
+
+	artifact := results.Artifact("artifact")
+	assert.Equal(t, "upload", artifact.Job)
+	assert.Equal(t, []string{"test-artifact.txt"}, artifact.Files)
+}
+
+func Test_seeded_artifact(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/artifacts.yaml").
+		WithJobName("download").
+		ConfigureArtifactServer(act_assert.ArtifactServerConfig{
+			T:    t,
+			Host: "host.docker.internal",
+		}).
+		SeedArtifactFromDir("artifact", "test/artifacts").
+		SeedArtifact("reports", map[string][]byte{"coverage/summary.txt": []byte("100%")}).
+		Plan()
+	assert.NoError(t, err)
+
+	err = workflow.Execute()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	downloadJob := results.Job("download")
+	assert.Equal(t, act_assert.Success, downloadJob.Result())
+	assert.Equal(t, "test-artifact.txt", downloadJob.Step("List files").Logs())
+
+	reports := results.Artifact("reports")
+	assert.Equal(t, "", reports.Job)
+	assert.Equal(t, []string{"coverage/summary.txt"}, reports.Files)
+}
+
+func Test_artifact_server_tied_to_test(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "artifacts")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "1", "logs"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "1", "logs", "build.log"), []byte("build ok"), 0o644))
+
+	t.Run("temp dir", func(t *testing.T) {
+		workflow := act_assert.New().
+			ConfigureArtifactServer(act_assert.ArtifactServerConfig{T: t})
+		artifacts, err := act_assert.NewResults(*workflow).Artifacts()
+		assert.NoError(t, err)
+		assert.Empty(t, artifacts)
+	})
+	t.Run("cleanup", func(t *testing.T) {
+		workflow := act_assert.New().
+			ConfigureArtifactServer(act_assert.ArtifactServerConfig{T: t, Path: dir, Cleanup: true})
+		assert.Equal(t, []string{"build.log"}, act_assert.NewResults(*workflow).Artifact("logs").Files)
+	})
+	assert.NoDirExists(t, dir)
 }
@@ -0,0 +1,355 @@
+package act_assert
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/wd-hopkins/act/pkg/artifactcache"
+)
+
+// cacheAPIPath is the path of the cache service API used by actions/cache.
+const cacheAPIPath = "/_apis/artifactcache"
+
+type CacheServerConfig struct {
+	// Path Defines the path where the cache server stores cache entries. If not specified, a temporary directory
+	// is used and removed after execution.
+	Path string
+}
+
+// CacheOperation is the kind of operation actions/cache performed against the cache server.
+type CacheOperation string
+
+const (
+	CacheRestore CacheOperation = "restore"
+	CacheSave    CacheOperation = "save"
+)
+
+// CacheEvent is a restore or save operation performed against the cache server.
+type CacheEvent struct {
+	Operation CacheOperation
+	// Key is the primary key of a restore, or the key of a saved entry.
+	Key string
+	// RestoreKeys are the fallback key prefixes of a restore.
+	RestoreKeys []string
+	Version     string
+	// Hit reports whether a restore found an entry.
+	Hit bool
+	// MatchedKey is the key of the entry a restore found.
+	MatchedKey string
+	Time       time.Time
+}
+
+type cacheSeed struct {
+	key   string
+	files map[string][]byte
+}
+
+// ConfigureCacheServer starts a cache server next to the artifact server when the workflow is executed and
+// points actions/cache, and the caching of the setup-* actions, at it.
+func (a *ActAssert) ConfigureCacheServer(config CacheServerConfig) *ActAssert {
+	a.cacheServerConfig = &config
+	return a
+}
+
+// SeedCache stores a cache entry with the given key before the workflow runs, so that the cache hit path of
+// jobs can be tested. files are keyed by their path in the archive, i.e. relative to the workspace or absolute.
+// The entry is restored by any restore whose key, or one of its restore keys, matches key. Entries are gzip
+// compressed tarballs, which actions/cache uses on runners without zstd, such as the default images. Requires
+// ConfigureCacheServer.
+func (a *ActAssert) SeedCache(key string, files map[string][]byte) *ActAssert {
+	a.cacheSeeds = append(a.cacheSeeds, cacheSeed{key: key, files: files})
+	return a
+}
+
+// CacheEvents returns the restore and save operations performed against the cache server, in order.
+func (r *Results) CacheEvents() []CacheEvent {
+	return r.cache.recordedEvents()
+}
+
+// cacheServer is a recording proxy in front of the act cache server, which seeds entries on demand.
+type cacheServer struct {
+	handler  *artifactcache.Handler
+	server   *http.Server
+	url      string
+	upstream string
+	seeds    []cacheSeed
+
+	mu     sync.Mutex
+	events []CacheEvent
+	// reserved contains the save events of the entries reserved but not yet committed, keyed by cache ID.
+	reserved map[string]CacheEvent
+	// seeded contains the seeding of the seeds with each version.
+	seeded map[string]*cacheSeeding
+}
+
+// cacheSeeding is the storing of the seeds with a version. Its mutex is held while they are stored, so that
+// restores with the version wait for them.
+type cacheSeeding struct {
+	mu sync.Mutex
+	// stored is the number of seeds stored, which are not stored again if storing the others failed.
+	stored int
+}
+
+// startCacheServer starts the act cache server, storing entries in dir, and a proxy recording the operations
+// against it, both reachable from job containers at addr.
+func startCacheServer(dir, addr string, seeds []cacheSeed) (*cacheServer, error) {
+	handler, err := artifactcache.StartHandler(dir, addr, 0, logrus.StandardLogger().WithField("module", "cache_request"))
+	if err != nil {
+		return nil, err
+	}
+	// The handler listens on every interface, but addr may only resolve inside containers
+	external, err := url.Parse(handler.ExternalURL())
+	if err != nil {
+		_ = handler.Close()
+		return nil, err
+	}
+	upstream := &url.URL{Scheme: "http", Host: net.JoinHostPort("localhost", external.Port())}
+	listener, proxyURL, err := listen(addr)
+	if err != nil {
+		_ = handler.Close()
+		return nil, err
+	}
+	s := &cacheServer{
+		handler:  handler,
+		url:      proxyURL + "/",
+		upstream: upstream.String(),
+		seeds:    seeds,
+		reserved: map[string]CacheEvent{},
+		seeded:   map[string]*cacheSeeding{},
+	}
+	proxy := httputil.NewSingleHostReverseProxy(upstream)
+	s.server = &http.Server{Handler: s.record(proxy)}
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+	return s, nil
+}
+
+func (s *cacheServer) close() {
+	_ = s.server.Close()
+	_ = s.handler.Close()
+}
+
+// record returns a handler recording the restore and save operations forwarded to next.
+func (s *cacheServer) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Read the request body first, as it is consumed when the request is forwarded
+		var body []byte
+		if r.Body != nil && r.Method == http.MethodPost {
+			body, _ = io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		path := strings.TrimPrefix(r.URL.Path, cacheAPIPath)
+		var keys []string
+		if r.Method == http.MethodGet && path == "/cache" {
+			keys = strings.Split(r.URL.Query().Get("keys"), ",")
+			if err := s.seed(r.URL.Query().Get("version")); err != nil {
+				logrus.Errorf("seeding cache: %v", err)
+			}
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && path == "/cache":
+			event := CacheEvent{Operation: CacheRestore, Key: keys[0], RestoreKeys: keys[1:],
+				Version: r.URL.Query().Get("version"), Time: time.Now()}
+			if recorder.status == http.StatusOK {
+				var found struct {
+					CacheKey string `json:"cacheKey"`
+				}
+				_ = json.Unmarshal(recorder.body.Bytes(), &found)
+				event.Hit, event.MatchedKey = true, found.CacheKey
+			}
+			s.events = append(s.events, event)
+		case r.Method == http.MethodPost && path == "/caches" && recorder.status == http.StatusOK:
+			var reserve struct {
+				Key     string `json:"key"`
+				Version string `json:"version"`
+			}
+			var reserved struct {
+				CacheID json.Number `json:"cacheId"`
+			}
+			_ = json.Unmarshal(body, &reserve)
+			_ = json.Unmarshal(recorder.body.Bytes(), &reserved)
+			s.reserved[reserved.CacheID.String()] = CacheEvent{Operation: CacheSave, Key: reserve.Key, Version: reserve.Version}
+		case r.Method == http.MethodPost && strings.HasPrefix(path, "/caches/") && recorder.status == http.StatusOK:
+			id := strings.TrimPrefix(path, "/caches/")
+			if event, ok := s.reserved[id]; ok {
+				event.Time = time.Now()
+				s.events = append(s.events, event)
+				delete(s.reserved, id)
+			}
+		}
+	})
+}
+
+// seed stores the seeded entries with version, unless they are already stored, leaving the matching of keys
+// to the cache server. The version of an entry is derived from the paths it caches, which seeds do not know.
+// Concurrent calls with the same version return once the entries are stored.
+func (s *cacheServer) seed(version string) error {
+	s.mu.Lock()
+	seeding, ok := s.seeded[version]
+	if !ok {
+		seeding = &cacheSeeding{}
+		s.seeded[version] = seeding
+	}
+	s.mu.Unlock()
+
+	seeding.mu.Lock()
+	defer seeding.mu.Unlock()
+	for ; seeding.stored < len(s.seeds); seeding.stored++ {
+		seed := s.seeds[seeding.stored]
+		if err := s.upload(seed, version); err != nil {
+			return fmt.Errorf("cache entry '%s': %w", seed.key, err)
+		}
+	}
+	return nil
+}
+
+// upload stores a seeded entry in the cache server, the way actions/cache saves entries.
+func (s *cacheServer) upload(seed cacheSeed, version string) error {
+	archive, err := cacheArchive(seed.files)
+	if err != nil {
+		return err
+	}
+	reserve, _ := json.Marshal(map[string]any{"key": seed.key, "version": version, "cacheSize": len(archive)})
+	var reserved struct {
+		CacheID json.Number `json:"cacheId"`
+	}
+	if err := s.call(http.MethodPost, "/caches", nil, reserve, &reserved); err != nil {
+		return err
+	}
+	header := http.Header{"Content-Range": {fmt.Sprintf("bytes 0-%d/*", len(archive)-1)}}
+	if err := s.call(http.MethodPatch, "/caches/"+reserved.CacheID.String(), header, archive, nil); err != nil {
+		return err
+	}
+	commit, _ := json.Marshal(map[string]any{"size": len(archive)})
+	return s.call(http.MethodPost, "/caches/"+reserved.CacheID.String(), nil, commit, nil)
+}
+
+// call calls the cache server API, decoding the response into result if it is not nil.
+func (s *cacheServer) call(method, path string, header http.Header, body []byte, result any) error {
+	req, err := http.NewRequest(method, s.upstream+cacheAPIPath+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(b)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (s *cacheServer) recordedEvents() []CacheEvent {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.events)
+}
+
+// cacheArchive returns files as a gzip compressed tarball, the archive format of actions/cache without zstd.
+func cacheArchive(files map[string][]byte) ([]byte, error) {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	archive := tar.NewWriter(gz)
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		header := &tar.Header{Name: path, Mode: 0o644, Size: int64(len(files[path])), ModTime: time.Now()}
+		if err := archive.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := archive.Write(files[path]); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// startCacheServer starts the configured cache server and returns its URL and a function stopping it. The URL
+// is empty if no cache server is configured.
+func (a *ActAssert) startCacheServer() (string, func(), error) {
+	if a.cacheServerConfig == nil {
+		if len(a.cacheSeeds) > 0 {
+			return "", nil, fmt.Errorf("seeding the cache requires the cache server. Did you forget to call ConfigureCacheServer()?")
+		}
+		return "", func() {}, nil
+	}
+	dir := a.cacheServerConfig.Path
+	if dir == "" {
+		var err error
+		if dir, err = os.MkdirTemp("", "act-assert-cache-"); err != nil {
+			return "", nil, err
+		}
+	}
+	server, err := startCacheServer(dir, a.artifactServerAddr, a.cacheSeeds)
+	if err != nil {
+		if a.cacheServerConfig.Path == "" {
+			_ = os.RemoveAll(dir)
+		}
+		return "", nil, fmt.Errorf("cache server: %w", err)
+	}
+	a.cache = server
+	return server.url, func() {
+		server.close()
+		if a.cacheServerConfig.Path == "" {
+			_ = os.RemoveAll(dir)
+		}
+	}, nil
+}
+
+// responseRecorder captures the status and body of a response while writing it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
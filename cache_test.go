@@ -0,0 +1,84 @@
+package act_assert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_cache_miss(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/cache.yaml").
+		ConfigureCacheServer(act_assert.CacheServerConfig{}).
+		Plan()
+	assert.NoError(t, err)
+
+	err = workflow.Execute()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("build")
+	assert.Equal(t, "false", job.Step("Restore dependencies").Outputs()["cache-hit"])
+	assert.Equal(t, act_assert.Success, job.Step("Install dependencies").Result())
+	assert.Equal(t, "installed", job.Step("Show dependencies").Logs())
+
+	events := results.CacheEvents()
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, act_assert.CacheRestore, events[0].Operation)
+		assert.Equal(t, "deps-v1", events[0].Key)
+		assert.False(t, events[0].Hit)
+		assert.Equal(t, act_assert.CacheSave, events[1].Operation)
+		assert.Equal(t, "deps-v1", events[1].Key)
+	}
+}
+
+func Test_seeded_cache_hit(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/cache.yaml").
+		ConfigureCacheServer(act_assert.CacheServerConfig{Path: t.TempDir()}).
+		SeedCache("deps-v1", map[string][]byte{"deps/state.txt": []byte("seeded")}).
+		Plan()
+	assert.NoError(t, err)
+
+	err = workflow.Execute()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("build")
+	assert.Equal(t, "true", job.Step("Restore dependencies").Outputs()["cache-hit"])
+	assert.Equal(t, act_assert.Skipped, job.Step("Install dependencies").Result())
+	assert.Equal(t, "seeded", job.Step("Show dependencies").Logs())
+
+	events := results.CacheEvents()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, act_assert.CacheRestore, events[0].Operation)
+		assert.True(t, events[0].Hit)
+		assert.Equal(t, "deps-v1", events[0].MatchedKey)
+	}
+}
+
+func Test_seeded_cache_hit_matrix(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/cache_matrix.yaml").
+		ConfigureCacheServer(act_assert.CacheServerConfig{Path: t.TempDir()}).
+		SeedCache("deps-v1", map[string][]byte{"deps/state.txt": []byte("seeded")}).
+		Plan()
+	assert.NoError(t, err)
+
+	err = workflow.Execute()
+	assert.NoError(t, err)
+
+	// Combinations restoring at the same time wait for the seed to be stored
+	results := act_assert.NewResults(*workflow)
+	for _, target := range []string{"linux", "windows", "darwin"} {
+		job := results.MatrixJob("build").Combination(map[string]any{"target": target})
+		assert.Equal(t, "true", job.Step("Restore dependencies").Outputs()["cache-hit"], target)
+		assert.Equal(t, "seeded", job.Step("Show dependencies").Logs(), target)
+	}
+	events := results.CacheEvents()
+	assert.Len(t, events, 3)
+	for _, event := range events {
+		assert.True(t, event.Hit)
+	}
+}
@@ -0,0 +1,147 @@
+package act_assert
+
+import (
+	"strings"
+
+	"github.com/rhysd/actionlint"
+	"github.com/wd-hopkins/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// cancelledCondition rewrites an `if:` condition for a workflow run that has been cancelled: cancelled()
+// is true, while success() and failure(), including the implicit success() of a condition without a status
+// function, are false. cancelled() is rewritten to always() so that the condition keeps a status function.
+// Conditions that do not parse are returned unchanged, for the runner to report.
+func cancelledCondition(condition string) string {
+	expr := strings.TrimSpace(condition)
+	if strings.HasPrefix(expr, "${{") && strings.HasSuffix(expr, "}}") {
+		expr = strings.TrimSpace(expr[3 : len(expr)-2])
+	}
+	node, err := actionlint.NewExprParser().Parse(actionlint.NewExprLexer(expr + "}}"))
+	if err != nil {
+		return condition
+	}
+
+	var rewritten strings.Builder
+	offset, found := 0, false
+	actionlint.VisitExprNode(node, func(node, _ actionlint.ExprNode, entering bool) {
+		call, ok := node.(*actionlint.FuncCallNode)
+		if !entering || !ok || len(call.Args) > 0 {
+			return
+		}
+		var replacement string
+		switch strings.ToLower(call.Callee) {
+		case "cancelled", "always":
+			replacement = "always()"
+		case "success", "failure":
+			replacement = "false"
+		default:
+			return
+		}
+		start := call.Token().Offset
+		end := statusCallEnd(expr, start+len(call.Callee))
+		rewritten.WriteString(expr[offset:start])
+		rewritten.WriteString(replacement)
+		offset, found = end, true
+	})
+	if !found {
+		return "false"
+	}
+	rewritten.WriteString(expr[offset:])
+	return rewritten.String()
+}
+
+// statusCallEnd returns the offset in expr following the empty argument list of a function call, given the
+// offset following the function name.
+func statusCallEnd(expr string, offset int) int {
+	for _, c := range "()" {
+		for offset < len(expr) && expr[offset] != byte(c) {
+			offset++
+		}
+		offset++
+	}
+	return min(offset, len(expr))
+}
+
+// applyCancellations makes the jobs and steps following a job or step whose result is set to Cancelled, or a
+// job calling a reusable workflow mocked with a cancelled stub, evaluate their `if:` conditions as in a
+// cancelled workflow run, which the runner does not support. Jobs with a cancelled step or stub are marked as
+// cancelled, unless they failed or were skipped, as soon as events records their result, so that the `needs`
+// context of the jobs depending on them is cancelled. The returned function marks them once more, for the runs
+// without events, and restores the conditions of the plan.
+func (a *ActAssert) applyCancellations(plan *model.Plan, events *eventRecorder) func() {
+	originalConditions := map[*yaml.Node]string{}
+	rewrite := func(node *yaml.Node) {
+		if _, ok := originalConditions[node]; !ok {
+			originalConditions[node] = node.Value
+		}
+		node.Value = cancelledCondition(node.Value)
+		if node.Kind == 0 {
+			node.Kind, node.Tag = yaml.ScalarNode, "!!str"
+		}
+	}
+
+	var cancelledJobs []*model.Run
+	for _, stage := range plan.Stages {
+		for _, run := range stage.Runs {
+			job := run.Job()
+			if job.Result == string(Cancelled) || a.cancelledByWorkflowMock(job) {
+				cancelledJobs = append(cancelledJobs, run)
+				continue
+			}
+			cancelled := false
+			for _, step := range job.Steps {
+				if cancelled && step.Result == "" {
+					rewrite(&step.If)
+				}
+				cancelled = cancelled || step.Result == string(Cancelled)
+			}
+			if cancelled {
+				cancelledJobs = append(cancelledJobs, run)
+			}
+		}
+	}
+
+	var dependentJobs []*model.Job
+	for _, stage := range plan.Stages {
+		for _, run := range stage.Runs {
+			job := run.Job()
+			for _, cancelledJob := range cancelledJobs {
+				if cancelledJob.Workflow == run.Workflow && dependsOn(run.Workflow, job, cancelledJob.JobID) {
+					dependentJobs = append(dependentJobs, job)
+					break
+				}
+			}
+		}
+	}
+	for _, job := range dependentJobs {
+		if job.Result == "" {
+			rewrite(&job.If)
+		}
+	}
+
+	if events != nil && len(cancelledJobs) > 0 {
+		// The runner logs the result of a job after setting it, before the jobs depending on it start
+		events.onEvent(func(event executionEvent) {
+			if event.data["jobResult"] != string(Success) {
+				return
+			}
+			for _, run := range cancelledJobs {
+				if run.JobID == event.jobID() {
+					run.Job().Result = string(Cancelled)
+				}
+			}
+		})
+	}
+
+	return func() {
+		for _, run := range cancelledJobs {
+			if job := run.Job(); job.Result == string(Success) {
+				job.Result = string(Cancelled)
+			}
+		}
+		for node, condition := range originalConditions {
+			node.Value = condition
+		}
+	}
+}
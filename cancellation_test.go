@@ -0,0 +1,90 @@
+package act_assert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_cancelled_job(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/cancellation.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("build").SetResult(act_assert.Cancelled)
+
+	err = workflow.ExecuteDryRun()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	assert.True(t, results.Job("build").Cancelled())
+	assert.Equal(t, act_assert.Skipped, results.Job("deploy").Result())
+	assert.Equal(t, act_assert.Success, results.Job("notify").Result())
+	assert.Equal(t, act_assert.Success, results.Job("notify").Step("notify").Result(), "needs.build.result is cancelled")
+	assert.Equal(t, act_assert.Success, results.Job("cleanup").Result())
+}
+
+func Test_cancelled_job_execute(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/cancellation.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("build").SetResult(act_assert.Cancelled)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	assert.True(t, results.Job("build").Cancelled())
+	assert.Equal(t, act_assert.Skipped, results.Job("deploy").Result())
+	assert.Equal(t, "build was cancelled", results.Job("notify").Step("notify").Logs())
+	assert.Equal(t, act_assert.Success, results.Job("cleanup").Result())
+}
+
+func Test_cancelled_step(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/cancellation.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("build").Step("compile").SetResult(act_assert.Cancelled)
+
+	err = workflow.ExecuteDryRun()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	build := results.Job("build")
+	assert.Equal(t, act_assert.Cancelled, build.Step("compile").Outcome())
+	assert.Equal(t, act_assert.Skipped, build.Step("test").Result())
+	assert.Equal(t, act_assert.Skipped, build.Step("lint").Result(), "status functions in strings are not calls")
+	assert.Equal(t, act_assert.Success, build.Step("report").Result())
+	assert.Equal(t, act_assert.Success, build.Step("cleanup").Result())
+	assert.True(t, build.Cancelled())
+	assert.Equal(t, act_assert.Skipped, results.Job("deploy").Result())
+	assert.Equal(t, act_assert.Success, results.Job("notify").Result())
+	assert.Equal(t, act_assert.Success, results.Job("notify").Step("notify").Result(), "needs.build.result is cancelled")
+}
+
+func Test_cancelled_step_execute(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/cancellation.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("build").Step("compile").SetResult(act_assert.Cancelled)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	build := results.Job("build")
+	assert.Equal(t, act_assert.Skipped, build.Step("test").Result())
+	assert.Equal(t, act_assert.Skipped, build.Step("lint").Result())
+	assert.Equal(t, "cancelled", build.Step("report").Logs())
+	assert.Equal(t, "cleaning up", build.Step("cleanup").Logs())
+	assert.True(t, build.Cancelled())
+	assert.Equal(t, act_assert.Skipped, results.Job("deploy").Result())
+	// The build is cancelled by the time the jobs depending on it run
+	assert.Equal(t, "build was cancelled", results.Job("notify").Step("notify").Logs())
+}
@@ -0,0 +1,105 @@
+// Command act-assert runs the declarative workflow test cases in `*.act-test.yaml` files.
+//
+// Usage:
+//
+//	act-assert [-dry-run] [path ...]
+//
+// Each path is a spec file or a directory searched recursively for spec files, defaulting to the current
+// directory. The exit code is non-zero if any test case fails.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+const specSuffix = ".act-test.yaml"
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "predict the results of every test case without running containers")
+	flag.Parse()
+	os.Exit(run(flag.Args(), *dryRun, os.Stdout))
+}
+
+func run(paths []string, dryRun bool, out io.Writer) int {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := discoverSpecs(paths)
+	if err != nil {
+		fmt.Fprintf(out, "error: %v\n", err)
+		return 2
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(out, "no %s files found\n", specSuffix)
+		return 2
+	}
+
+	failed := 0
+	for _, file := range files {
+		spec, err := act_assert.LoadSpec(file)
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "FAIL  %s\n    %v\n", file, err)
+			continue
+		}
+		spec.DryRun = spec.DryRun || dryRun
+		failures, err := spec.Run()
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+		if len(failures) == 0 {
+			fmt.Fprintf(out, "PASS  %s\n", spec.Name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(out, "FAIL  %s\n", spec.Name)
+		for _, failure := range failures {
+			fmt.Fprintf(out, "    %s\n", failure)
+		}
+	}
+
+	fmt.Fprintf(out, "\n%d passed, %d failed\n", len(files)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// discoverSpecs returns the spec files in paths, searching directories recursively.
+func discoverSpecs(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && (d.Name() == ".git" || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && strings.HasSuffix(d.Name(), specSuffix) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
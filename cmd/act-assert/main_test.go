@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSpec(t *testing.T, path, content string) {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func Test_discover_specs(t *testing.T) {
+	dir := t.TempDir()
+	writeSpec(t, filepath.Join(dir, "build.act-test.yaml"), "")
+	writeSpec(t, filepath.Join(dir, "nested", "deploy.act-test.yaml"), "")
+	writeSpec(t, filepath.Join(dir, "nested", "workflow.yaml"), "")
+	writeSpec(t, filepath.Join(dir, "node_modules", "ignored.act-test.yaml"), "")
+	writeSpec(t, filepath.Join(dir, ".git", "ignored.act-test.yaml"), "")
+	explicit := filepath.Join(t.TempDir(), "explicit.yaml")
+	writeSpec(t, explicit, "")
+
+	files, err := discoverSpecs([]string{dir, explicit})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "build.act-test.yaml"),
+		filepath.Join(dir, "nested", "deploy.act-test.yaml"),
+		explicit,
+	}, files)
+
+	_, err = discoverSpecs([]string{filepath.Join(dir, "missing")})
+	assert.Error(t, err)
+}
+
+func Test_run_specs(t *testing.T) {
+	// Specs resolve the workflow relative to their own directory, wherever the tool runs from
+	dir := t.TempDir()
+	workflow, err := os.ReadFile("../../test/dry_run.yaml")
+	assert.NoError(t, err)
+	writeSpec(t, filepath.Join(dir, "workflows", "dry_run.yaml"), string(workflow))
+	writeSpec(t, filepath.Join(dir, "specs", "pass.act-test.yaml"), `name: Build succeeds
+workflow: ../workflows/dry_run.yaml
+event: push
+expect:
+  build:
+    result: success
+`)
+	writeSpec(t, filepath.Join(dir, "specs", "fail.act-test.yaml"), `name: Build fails
+workflow: ../workflows/dry_run.yaml
+event: push
+expect:
+  build:
+    result: failure
+`)
+
+	var out bytes.Buffer
+	assert.Equal(t, 1, run([]string{dir}, true, &out))
+	assert.Contains(t, out.String(), "PASS  Build succeeds")
+	assert.Contains(t, out.String(), "FAIL  Build fails\n    job 'build': expected result 'failure', got 'success'")
+	assert.Contains(t, out.String(), "1 passed, 1 failed")
+
+	out.Reset()
+	assert.Equal(t, 0, run([]string{filepath.Join(dir, "specs", "pass.act-test.yaml")}, true, &out))
+
+	out.Reset()
+	assert.Equal(t, 2, run([]string{filepath.Join(dir, "workflows")}, true, &out))
+	assert.Contains(t, out.String(), "no .act-test.yaml files found")
+}
@@ -0,0 +1,287 @@
+package act_assert
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wd-hopkins/act/pkg/container"
+	"github.com/wd-hopkins/act/pkg/model"
+	"github.com/wd-hopkins/act/pkg/runner"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// commandStubsDir is the directory in the job container holding a directory per stubbed command.
+	commandStubsDir = "/tmp/act-assert/bin"
+	// commandEnvDir is the directory in the job container holding the script putting the stubs on the PATH.
+	commandEnvDir = "/tmp/act-assert/env"
+	// commandEnvScript is the script bash sources through $BASH_ENV, prepending the stubs to the PATH.
+	commandEnvScript = commandEnvDir + "/path.sh"
+	// commandCallsDir is the directory in the job container where stubbed commands record their calls.
+	// It is bind mounted from a temporary directory on the host while the workflow is executed.
+	commandCallsDir = "/tmp/act-assert/calls"
+	// stepEnvKey identifies the step that invoked a stubbed command by its index in the job.
+	stepEnvKey = "ACT_ASSERT_STEP"
+	// matrixEnvKey identifies the matrix combination that invoked a stubbed command.
+	matrixEnvKey = "ACT_ASSERT_MATRIX"
+	// bashEnvKey is the $BASH_ENV set by the workflow, sourced by the script putting the stubs on the PATH.
+	bashEnvKey = "ACT_ASSERT_BASH_ENV"
+)
+
+// CommandStub scripts the behaviour of a stubbed command. Stubs are put on the PATH through $BASH_ENV, so they
+// only apply to steps running bash, the default shell of run steps. Steps running sh, or another shell, and
+// actions call the real command.
+type CommandStub struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// CommandCall is a recorded invocation of a stubbed command.
+type CommandCall struct {
+	// Args are the arguments the command was called with, excluding the command name.
+	Args []string
+	// Env is the environment the command was called with.
+	Env map[string]string
+}
+
+// commandCall is a call of a stubbed command, along with the job, matrix combination and step that made it.
+type commandCall struct {
+	CommandCall
+	name   string
+	jobID  string
+	matrix string
+	step   string
+}
+
+// StubCommand replaces the executable name in the job container with a stub that prints the scripted
+// stdout and stderr and exits with the scripted exit code. The stub applies to the steps of the job running
+// bash, see CommandStub, and records the arguments and environment of each call, see StepResults.CommandCalls.
+func (j *JobPlan) StubCommand(name string, stub CommandStub) *JobPlan {
+	if j.jobRun.FileMounts == nil {
+		j.jobRun.FileMounts = map[string]*container.FileEntry{}
+	}
+	j.jobRun.FileMounts[path.Join(commandStubsDir, name)] = &container.FileEntry{
+		Name: name,
+		Mode: 0o755,
+		Body: commandStubScript(name, stub),
+	}
+
+	var stubDirs []string
+	for dir := range j.jobRun.FileMounts {
+		if strings.HasPrefix(dir, commandStubsDir+"/") {
+			stubDirs = append(stubDirs, dir)
+		}
+	}
+	slices.Sort(stubDirs)
+	j.jobRun.FileMounts[commandEnvDir] = &container.FileEntry{
+		Name: path.Base(commandEnvScript),
+		Mode: 0o644,
+		Body: fmt.Sprintf("export PATH=%s:\"$PATH\"\n[ -z \"$%s\" ] || . \"$%[2]s\"\n",
+			shellQuote(strings.Join(stubDirs, ":")), bashEnvKey),
+	}
+	return j
+}
+
+// hasCommandStubs reports whether commands are stubbed in the job run runs.
+func hasCommandStubs(run *model.Run) bool {
+	_, ok := run.FileMounts[commandEnvDir]
+	return ok
+}
+
+// applyCommandStubs bind mounts a temporary directory recording the calls of the stubbed commands into the
+// jobs stubbing commands, and makes their steps put the stubs on the PATH and record the step and matrix
+// combination running them. The $BASH_ENV set by the workflow is sourced after the stubs are put on the PATH.
+// The returned function reads the recorded calls, removes the directory and restores the plan.
+func (a *ActAssert) applyCommandStubs() (func(), error) {
+	var runs []*model.Run
+	for _, stage := range a.plan.Stages {
+		for _, run := range stage.Runs {
+			if hasCommandStubs(run) {
+				runs = append(runs, run)
+			}
+		}
+	}
+	a.commandCalls = nil
+	if len(runs) == 0 {
+		return func() {}, nil
+	}
+
+	dir, err := hostTempDir("act-assert-calls-*")
+	if err != nil {
+		return nil, fmt.Errorf("command stubs: %w", err)
+	}
+	originalBindMounts := map[*model.Run][]string{}
+	originalEnvs := map[*model.Step]yaml.Node{}
+	originalEnvOverrides := map[*model.Step]map[string]string{}
+	for _, run := range runs {
+		originalBindMounts[run] = run.BindMounts
+		run.BindMounts = append(slices.Clone(run.BindMounts), dir+":"+commandCallsDir)
+		for i, step := range run.Job().Steps {
+			originalEnvs[step] = step.Env
+			bashEnv := "${{ env.BASH_ENV }}"
+			if step.Env.Kind == yaml.MappingNode && mappingValue(&step.Env, "BASH_ENV") != "" {
+				bashEnv = mappingValue(&step.Env, "BASH_ENV")
+			}
+			setStepEnv(step, bashEnvKey, bashEnv)
+			setStepEnv(step, matrixEnvKey, "${{ toJSON(matrix) }}")
+
+			originalEnvOverrides[step] = step.EnvOverrides
+			step.EnvOverrides = maps.Clone(step.EnvOverrides)
+			if step.EnvOverrides == nil {
+				step.EnvOverrides = map[string]string{}
+			}
+			step.EnvOverrides["BASH_ENV"] = commandEnvScript
+			step.EnvOverrides[stepEnvKey] = strconv.Itoa(i)
+		}
+	}
+	return func() {
+		a.commandCalls = readCommandCalls(dir)
+		_ = os.RemoveAll(dir)
+		for run, bindMounts := range originalBindMounts {
+			run.BindMounts = bindMounts
+		}
+		for step, env := range originalEnvs {
+			step.Env = env
+		}
+		for step, envOverrides := range originalEnvOverrides {
+			step.EnvOverrides = envOverrides
+		}
+	}, nil
+}
+
+// setStepEnv sets the environment variable k of the step to the expression v, evaluated by the runner,
+// without modifying the mapping shared with the original environment of the step.
+func setStepEnv(step *model.Step, k, v string) {
+	env := step.Env
+	if env.Kind != yaml.MappingNode {
+		env = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	env.Content = slices.Clone(env.Content)
+	setMappingValue(&env, k, v)
+	step.Env = env
+}
+
+// hostTempDir creates a temporary directory on the host to bind mount into job containers.
+func hostTempDir(pattern string) (string, error) {
+	dir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	// The job container may run as a different user than the tests
+	if err := os.Chmod(dir, 0o777); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// shellQuote quotes s as a single word for the shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func commandStubScript(name string, stub CommandStub) string {
+	return fmt.Sprintf(`#!/bin/sh
+call_dir="%[1]s/%[2]s/$(date +%%s%%N)-$$"
+mkdir -p "$call_dir"
+printf '%%s\0' "$@" > "$call_dir/args"
+env -0 > "$call_dir/env" 2>/dev/null || env > "$call_dir/env"
+printf '%%s' '%[3]s' | base64 -d
+printf '%%s' '%[4]s' | base64 -d >&2
+exit %[5]d
+`, commandCallsDir, name,
+		base64.StdEncoding.EncodeToString([]byte(stub.Stdout)),
+		base64.StdEncoding.EncodeToString([]byte(stub.Stderr)),
+		stub.ExitCode)
+}
+
+// readCommandCalls returns the calls of the stubbed commands recorded in dir, in call order per command.
+func readCommandCalls(dir string) []commandCall {
+	commands, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var calls []commandCall
+	for _, command := range commands {
+		entries, err := os.ReadDir(filepath.Join(dir, command.Name()))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			callDir := filepath.Join(dir, command.Name(), entry.Name())
+			args, err := os.ReadFile(filepath.Join(callDir, "args"))
+			if err != nil {
+				continue
+			}
+			env, err := os.ReadFile(filepath.Join(callDir, "env"))
+			if err != nil {
+				continue
+			}
+			call := commandCall{
+				CommandCall: CommandCall{Args: splitRecord(args), Env: map[string]string{}},
+				name:        command.Name(),
+			}
+			for _, kv := range splitRecord(env) {
+				if k, v, ok := strings.Cut(kv, "="); ok {
+					call.Env[k] = v
+				}
+			}
+			var matrix map[string]any
+			_ = json.Unmarshal([]byte(call.Env[matrixEnvKey]), &matrix)
+			call.jobID, call.matrix, call.step = call.Env["GITHUB_JOB"], matrixKey(matrix), call.Env[stepEnvKey]
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// commandCalls returns the recorded calls of the stubbed command name made by the job, or matrix combination
+// of the job, runContext runs, in call order. If step is not negative, only the calls made by the step with
+// that index are returned.
+func (r *Results) commandCalls(runContext *runner.RunContext, name string, step int) []CommandCall {
+	var calls []CommandCall
+	for _, call := range r.recordedCommandCalls {
+		if call.name != name || call.jobID != runContext.Run.JobID || call.matrix != matrixKey(runContext.Matrix) {
+			continue
+		}
+		if step >= 0 && call.step != strconv.Itoa(step) {
+			continue
+		}
+		calls = append(calls, call.CommandCall)
+	}
+	return calls
+}
+
+// matrixKey returns a key identifying the matrix combination.
+func matrixKey(matrix map[string]any) string {
+	if len(matrix) == 0 {
+		return "{}"
+	}
+	key, _ := json.Marshal(matrix)
+	return string(key)
+}
+
+// splitRecord splits a NUL separated record, or a newline separated one if it contains no NUL characters.
+func splitRecord(record []byte) []string {
+	sep := []byte{0}
+	if !bytes.Contains(record, sep) {
+		sep = []byte{'\n'}
+	}
+	var fields []string
+	for _, field := range bytes.Split(record, sep) {
+		if len(field) > 0 {
+			fields = append(fields, string(field))
+		}
+	}
+	return fields
+}
@@ -0,0 +1,94 @@
+package act_assert_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_stub_command(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/commands.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("deploy").
+		StubCommand("kubectl", act_assert.CommandStub{Stdout: "deployment.apps/app configured\n"})
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("deploy")
+	apply := job.Step("Apply manifests")
+	assert.Equal(t, "deployment.apps/app configured", apply.Logs())
+	calls := apply.CommandCalls("kubectl")
+	if assert.Len(t, calls, 1) {
+		assert.Equal(t, []string{"apply", "-f", "manifests/", "--context", "staging"}, calls[0].Args)
+		assert.Equal(t, "staging", calls[0].Env["CLUSTER"])
+		assert.True(t, strings.HasPrefix(calls[0].Env["PATH"], "/tmp/act-assert/bin/kubectl:"))
+		assert.Contains(t, calls[0].Env["PATH"], ":/usr/bin")
+	}
+	assert.Len(t, job.CommandCalls("kubectl"), 2)
+}
+
+func Test_stub_command_exit_code(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/commands.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("deploy").
+		StubCommand("kubectl", act_assert.CommandStub{Stderr: "timed out waiting for rollout", ExitCode: 1})
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("deploy")
+	apply := job.Step("Apply manifests")
+	assert.Equal(t, act_assert.Failure, apply.Result())
+	assert.Contains(t, apply.Logs(), "timed out waiting for rollout")
+	assert.Equal(t, act_assert.Skipped, job.Step("Check rollout").Result())
+}
+
+func Test_stub_command_matrix(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/commands.yaml").
+		WithJobName("deploy_matrix").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("deploy_matrix").
+		StubCommand("kubectl", act_assert.CommandStub{})
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	for _, cluster := range []string{"staging", "production"} {
+		job := results.MatrixJob("deploy_matrix").Combination(map[string]any{"cluster": cluster})
+		calls := job.Step("0").CommandCalls("kubectl")
+		if assert.Len(t, calls, 1) {
+			assert.Equal(t, []string{"apply", "-f", "manifests/", "--context", cluster}, calls[0].Args)
+		}
+	}
+}
+
+func Test_stub_command_keeps_bash_env(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/commands.yaml").
+		WithJobName("deploy_bash_env").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("deploy_bash_env").
+		StubCommand("kubectl", act_assert.CommandStub{Stdout: "v1.30.0\n"})
+
+	err = workflow.Execute()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	step := results.Job("deploy_bash_env").Step("Use helpers")
+	assert.Equal(t, "hello\nv1.30.0", step.Logs())
+	assert.Len(t, step.CommandCalls("kubectl"), 1)
+}
@@ -0,0 +1,414 @@
+package act_assert
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/wd-hopkins/act/pkg/model"
+	"github.com/wd-hopkins/act/pkg/runner"
+)
+
+// ExecuteDryRun predicts the results of the planned workflow without running it. The job and step `if:`
+// conditions are evaluated with the act expression evaluator in the order the jobs would run, using the
+// results and outputs set with JobPlan and StepPlan. Steps without an overridden result are predicted to
+// succeed. No containers are started, so a Docker socket is not required.
+//
+// The plan is left unchanged, so it can be dry run again with different overrides. The predicted results are
+// available with NewResults, even if an error is returned. The returned error is an *ExecutionError of kind
+// WorkflowError if any job is predicted to fail or an expression cannot be evaluated.
+func (a *ActAssert) ExecuteDryRun() error {
+	if a.plan == nil {
+		return infrastructureError(errNoPlan)
+	}
+	if _, err := a.eventContext(); err != nil {
+		return infrastructureError(err)
+	}
+	plan := clonePlan(a.plan)
+	restoreStepFailures := a.applyStepFailures(plan)
+	finishMatrixOverrides := a.applyMatrixOverrides(plan)
+	_ = a.applyCancellations(plan, nil)
+	runnerConfig := a.runnerConfig()
+	a.events = nil
+	a.runContexts = nil
+
+	var errs []error
+	for _, stage := range plan.Stages {
+		for _, run := range stage.Runs {
+			runContexts, err := a.dryRunJob(run, runnerConfig)
+			a.runContexts = append(a.runContexts, runContexts...)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("job '%s': %w", run.JobID, err))
+			}
+		}
+	}
+	finishMatrixOverrides()
+	restoreStepFailures()
+
+	var failedJobs []string
+	for _, runContext := range a.runContexts {
+		if runContext.Run.Job().Result == string(Failure) {
+			failedJobs = append(failedJobs, runContext.JobName)
+		}
+	}
+	if len(failedJobs) == 0 && len(errs) == 0 {
+		return nil
+	}
+	err := errors.Join(errs...)
+	if err == nil {
+		err = errors.New("one or more jobs are predicted to fail")
+	}
+	return &ExecutionError{Kind: WorkflowError, FailedJobs: failedJobs, Err: err}
+}
+
+// dryRunJob predicts the results of the job run executes, with a run context per matrix combination.
+func (a *ActAssert) dryRunJob(run *model.Run, runnerConfig *runner.Config) ([]*runner.RunContext, error) {
+	job := run.Job()
+	evaluator, err := a.jobEvaluator(a.newDryRunContext(run, nil, runnerConfig), nil)
+	if err != nil {
+		return nil, err
+	}
+	matrixes, err := a.dryRunMatrixes(job, evaluator)
+	if err != nil {
+		job.Result = string(Failure)
+		return []*runner.RunContext{a.newDryRunContext(run, nil, runnerConfig)}, err
+	}
+	if len(matrixes) == 1 {
+		runContext, err := a.dryRunMatrix(run, matrixes[0], job.Strategy, 0, 1, runnerConfig)
+		return []*runner.RunContext{runContext}, err
+	}
+
+	var runContexts []*runner.RunContext
+	var errs []error
+	outputs := map[string]string{}
+	failFast := job.Strategy != nil && job.Strategy.GetFailFast()
+	for i, matrix := range matrixes {
+		leg := cloneRun(run)
+		var runContext *runner.RunContext
+		var err error
+		if failFast && matrixResult(runContexts) == Failure {
+			// A failed combination cancels the remaining ones
+			leg.Job().Result = string(Cancelled)
+			runContext = a.newDryRunContext(leg, matrix, runnerConfig)
+		} else {
+			runContext, err = a.dryRunMatrix(leg, matrix, job.Strategy, i, len(matrixes), runnerConfig)
+		}
+		runContext.Name = fmt.Sprintf("%s-%d", runContext.Name, i+1)
+		runContexts = append(runContexts, runContext)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for k, v := range leg.Job().Outputs {
+			if v != "" {
+				outputs[k] = v
+			}
+		}
+	}
+	job.Outputs = outputs
+	job.Result = string(matrixResult(runContexts))
+	return runContexts, errors.Join(errs...)
+}
+
+// dryRunMatrixes returns the matrix combinations of job, evaluating expressions in the matrix and applying
+// the matrix filter set with WithMatrix.
+func (a *ActAssert) dryRunMatrixes(job *model.Job, evaluator *expressionEvaluator) ([]map[string]any, error) {
+	if job.Strategy == nil {
+		return []map[string]any{nil}, nil
+	}
+	strategy := *job.Strategy
+	strategy.RawMatrix = *copyYamlNode(&job.Strategy.RawMatrix)
+	if err := evaluator.evaluateYamlNode(&strategy.RawMatrix); err != nil {
+		return nil, err
+	}
+	expanded := *job
+	expanded.Strategy = &strategy
+	matrixes, err := expanded.GetMatrixes()
+	if err != nil {
+		return nil, err
+	}
+	matrixes = selectMatrixes(matrixes, a.matrix)
+	if len(matrixes) == 0 {
+		return []map[string]any{nil}, nil
+	}
+	return matrixes, nil
+}
+
+// selectMatrixes returns the matrix combinations allowed by filter, as the runner does.
+func selectMatrixes(matrixes []map[string]any, filter map[string]map[string]bool) []map[string]any {
+	var selected []map[string]any
+	for _, matrix := range matrixes {
+		allowed := true
+		for k, v := range matrix {
+			if values, ok := filter[k]; ok && !values[fmt.Sprint(v)] {
+				allowed = false
+			}
+		}
+		if allowed {
+			selected = append(selected, matrix)
+		}
+	}
+	return selected
+}
+
+// dryRunMatrix predicts the result of a single matrix combination of the job run executes.
+func (a *ActAssert) dryRunMatrix(run *model.Run, matrix map[string]any, strategy *model.Strategy, index, total int,
+	runnerConfig *runner.Config) (*runner.RunContext, error) {
+	job := run.Job()
+	_ = a.mockRunActions(run)
+	runContext := a.newDryRunContext(run, matrix, runnerConfig)
+
+	strategyContext := map[string]any{"job-index": index, "job-total": total}
+	if strategy != nil {
+		strategyContext["fail-fast"] = strategy.GetFailFast()
+		strategyContext["max-parallel"] = strategy.GetMaxParallel()
+	}
+	evaluator, err := a.jobEvaluator(runContext, strategyContext)
+	if err != nil {
+		return runContext, err
+	}
+	if job.Name != "" {
+		name, err := evaluator.interpolate(job.Name)
+		if err != nil {
+			job.Result = string(Failure)
+			return runContext, err
+		}
+		runContext.Name, runContext.JobName = name, name
+	}
+
+	jobEnv, err := evaluator.interpolateMap(job.Environment())
+	if err != nil {
+		job.Result = string(Failure)
+		return runContext, err
+	}
+	maps.Copy(evaluator.env.Env, jobEnv)
+	runContext.Env = maps.Clone(evaluator.env.Env)
+	evaluator.env.Steps = runContext.StepResults
+
+	if job.Result == "" {
+		enabled, err := evaluator.evaluateCondition(job.If.Value)
+		if err != nil {
+			job.Result = string(Failure)
+			return runContext, err
+		}
+		if !enabled {
+			job.Result = string(Skipped)
+		}
+	}
+	if job.Result != "" {
+		return runContext, nil
+	}
+	if job.Uses != "" {
+		runContext.WithEvaluated, err = a.dryRunReusableWorkflow(job, evaluator)
+		if err != nil {
+			job.Result = string(Failure)
+		}
+		return runContext, err
+	}
+
+	evaluator.config.Context = "step"
+	for i, step := range job.Steps {
+		// The runner identifies steps without an ID by their index
+		step.ID = stepID(step, i)
+		if err := a.dryRunStep(runContext, evaluator, step); err != nil {
+			step.Result = string(Failure)
+			job.Result = string(Failure)
+			return runContext, fmt.Errorf("step '%s': %w", step.String(), err)
+		}
+	}
+	evaluator.env.Env = runContext.Env
+	evaluator.config.Context = "job"
+
+	job.Result = evaluator.env.Job.Status
+	outputs, err := evaluator.interpolateMap(job.Outputs)
+	if err != nil {
+		return runContext, err
+	}
+	job.Outputs = outputs
+	return runContext, nil
+}
+
+// dryRunStep predicts the result of step from its `if:` condition and the overrides set on the plan.
+func (a *ActAssert) dryRunStep(runContext *runner.RunContext, evaluator *expressionEvaluator, step *model.Step) error {
+	env := maps.Clone(runContext.Env)
+	evaluator.env.Env = env
+	stepEnv, err := evaluator.interpolateMap(step.Environment())
+	if err != nil {
+		return err
+	}
+	maps.Copy(env, stepEnv)
+	maps.Copy(env, step.EnvOverrides)
+	for k, v := range step.With {
+		input, err := evaluator.interpolate(v)
+		if err != nil {
+			return err
+		}
+		env[inputEnvKey(k)] = input
+	}
+	step.EnvEvaluated = env
+
+	result := Result(step.Result)
+	if result == "" {
+		enabled, err := evaluator.evaluateCondition(step.If.Value)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			result = Skipped
+		}
+	}
+
+	outputs := map[string]string{}
+	if _, ok := step.EnvOverrides[failExitCodeEnvKey]; ok && result == "" {
+		// The step was replaced with a failing one by StepPlan.Fail
+		result = Failure
+		stderr, _ := base64.StdEncoding.DecodeString(step.EnvOverrides[failStderrEnvKey])
+		step.Logs = strings.TrimSuffix(string(stderr), "\n")
+	}
+	if result == "" {
+		result = Success
+		if runContext.Run.StepResultsFunc != nil {
+			if ok, stepResult := runContext.Run.StepResultsFunc(step); ok {
+				result = Result(stepResult)
+			}
+		}
+		if runContext.Run.StepOutputsFunc != nil {
+			maps.Copy(outputs, runContext.Run.StepOutputsFunc(step))
+		}
+	}
+
+	conclusion := result
+	if result == Failure {
+		continueOnError, err := evaluator.interpolate(step.RawContinueOnError)
+		if err != nil {
+			return err
+		}
+		if continueOnError == "true" {
+			conclusion = Success
+		}
+	}
+	step.Result = string(conclusion)
+	runContext.StepResults[step.ID] = newStepResult(result, conclusion, outputs)
+	switch {
+	case conclusion == Failure:
+		evaluator.env.Job.Status = string(Failure)
+	case conclusion == Cancelled && evaluator.env.Job.Status != string(Failure):
+		evaluator.env.Job.Status = string(Cancelled)
+	}
+	return nil
+}
+
+// newStepResult returns the result of a step for the `steps` context. The runner has no cancelled step status,
+// so cancelled steps have a failure outcome and conclusion.
+func newStepResult(outcome, conclusion Result, outputs map[string]string) *model.StepResult {
+	stepResult := &model.StepResult{Outputs: outputs}
+	switch outcome {
+	case Failure, Cancelled:
+		stepResult.Outcome = model.StepStatusFailure
+	case Skipped:
+		stepResult.Outcome = model.StepStatusSkipped
+	}
+	switch conclusion {
+	case Failure, Cancelled:
+		stepResult.Conclusion = model.StepStatusFailure
+	case Skipped:
+		stepResult.Conclusion = model.StepStatusSkipped
+	}
+	return stepResult
+}
+
+// newDryRunContext returns a run context for the job run executes, with the fields the runner sets before
+// evaluating the expressions of the job, so that it evaluates them the same way.
+func (a *ActAssert) newDryRunContext(run *model.Run, matrix map[string]any, runnerConfig *runner.Config) *runner.RunContext {
+	// The event payload is checked by ExecuteDryRun
+	event, _ := a.eventContext()
+	eventJSON, _ := json.Marshal(event)
+	return &runner.RunContext{
+		Name:        run.JobID,
+		JobName:     run.JobID,
+		Config:      runnerConfig,
+		Run:         run,
+		Matrix:      matrix,
+		EventJSON:   string(eventJSON),
+		StepResults: map[string]*model.StepResult{},
+		Env:         map[string]string{},
+	}
+}
+
+// matrixResult rolls the results of the matrix combinations of a job up into the result of the job.
+func matrixResult(runContexts []*runner.RunContext) Result {
+	skipped, cancelled := true, false
+	for _, runContext := range runContexts {
+		switch Result(runContext.Run.Job().Result) {
+		case Failure:
+			return Failure
+		case Cancelled:
+			cancelled = true
+		case Skipped:
+			continue
+		}
+		skipped = false
+	}
+	switch {
+	case cancelled:
+		return Cancelled
+	case skipped:
+		return Skipped
+	default:
+		return Success
+	}
+}
+
+// clonePlan returns a copy of plan whose workflows, jobs and steps can be modified without affecting plan.
+func clonePlan(plan *model.Plan) *model.Plan {
+	workflows := map[*model.Workflow]*model.Workflow{}
+	clone := &model.Plan{}
+	for _, stage := range plan.Stages {
+		stageClone := &model.Stage{}
+		for _, run := range stage.Runs {
+			workflow, ok := workflows[run.Workflow]
+			if !ok {
+				workflow = cloneWorkflow(run.Workflow)
+				workflows[run.Workflow] = workflow
+			}
+			runClone := *run
+			runClone.Workflow = workflow
+			stageClone.Runs = append(stageClone.Runs, &runClone)
+		}
+		clone.Stages = append(clone.Stages, stageClone)
+	}
+	return clone
+}
+
+func cloneWorkflow(workflow *model.Workflow) *model.Workflow {
+	clone := *workflow
+	clone.Jobs = make(map[string]*model.Job, len(workflow.Jobs))
+	for id, job := range workflow.Jobs {
+		clone.Jobs[id] = cloneJob(job)
+	}
+	return &clone
+}
+
+// cloneRun returns a copy of run with its own copy of the job, sharing the other jobs of the workflow.
+func cloneRun(run *model.Run) *model.Run {
+	workflow := *run.Workflow
+	workflow.Jobs = maps.Clone(run.Workflow.Jobs)
+	workflow.Jobs[run.JobID] = cloneJob(run.Job())
+	clone := *run
+	clone.Workflow = &workflow
+	return &clone
+}
+
+func cloneJob(job *model.Job) *model.Job {
+	clone := *job
+	clone.Outputs = maps.Clone(job.Outputs)
+	clone.Steps = make([]*model.Step, len(job.Steps))
+	for i, step := range job.Steps {
+		stepClone := *step
+		stepClone.EnvOverrides = maps.Clone(step.EnvOverrides)
+		clone.Steps[i] = &stepClone
+	}
+	return &clone
+}
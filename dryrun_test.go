@@ -0,0 +1,91 @@
+package act_assert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_dry_run(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/dry_run.yaml").
+		WithEventPayload(act_assert.PushEvent{Ref: "refs/heads/main", After: "abc123"}).
+		Plan()
+	assert.NoError(t, err)
+
+	// Steps do not run in a dry run, so their outputs are set on the plan
+	workflow.Job("build").Step("version").SetOutputs(map[string]string{"version": "1.0.0"})
+
+	err = workflow.ExecuteDryRun()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	assert.Equal(t, act_assert.Success, results.Job("build").Result())
+	assert.Equal(t, "1.0.0", results.Job("build").Outputs()["version"])
+	assert.Equal(t, act_assert.Skipped, results.Job("build").Step("Report lint failure").Result())
+	assert.Equal(t, act_assert.Skipped, results.Job("build").Step("Notify failure").Result())
+
+	test := results.MatrixJob("test")
+	assert.Len(t, test, 2)
+	assert.True(t, test.AllSucceeded())
+	assert.Equal(t, act_assert.Success, test.Combination(map[string]any{"os": "windows"}).Step("Windows only").Result())
+	assert.Equal(t, act_assert.Skipped, test.Combination(map[string]any{"os": "linux"}).Step("Windows only").Result())
+
+	assert.Equal(t, act_assert.Success, results.Job("deploy").Result())
+	assert.Equal(t, act_assert.Success, results.Job("cleanup").Result())
+}
+
+func Test_dry_run_with_overrides(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/dry_run.yaml").
+		WithEventPayload(act_assert.PushEvent{Ref: "refs/heads/main"}).
+		Plan()
+	assert.NoError(t, err)
+
+	build := workflow.Job("build")
+	build.Step("lint").SetResult(act_assert.Failure)
+
+	err = workflow.ExecuteDryRun()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	assert.Equal(t, act_assert.Failure, results.Job("build").Step("lint").Outcome())
+	assert.Equal(t, act_assert.Success, results.Job("build").Step("lint").Conclusion())
+	assert.Equal(t, act_assert.Success, results.Job("build").Step("Report lint failure").Result())
+	assert.Equal(t, act_assert.Success, results.Job("build").Result())
+
+	build.Step("version").SetResult(act_assert.Failure)
+
+	// The plan is left unchanged by a dry run, so it can be dry run again with other overrides
+	err = workflow.ExecuteDryRun()
+	assert.True(t, act_assert.IsWorkflowError(err))
+
+	results = act_assert.NewResults(*workflow)
+	assert.Equal(t, act_assert.Failure, results.Job("build").Result())
+	assert.Equal(t, act_assert.Success, results.Job("build").Step("Notify failure").Result())
+	assert.Equal(t, act_assert.Skipped, results.Job("test").Result())
+	assert.Equal(t, act_assert.Skipped, results.Job("deploy").Result())
+	assert.Equal(t, act_assert.Success, results.Job("cleanup").Result())
+}
+
+func Test_dry_run_step_ids_and_names(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/dry_run.yaml").
+		WithEventPayload(act_assert.PushEvent{Ref: "refs/heads/main"}).
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("build").Step("version").SetOutputs(map[string]string{"version": "1.0.0"})
+	workflow.Job("deploy").Step("0").Fail(1, "deploy failed")
+
+	err = workflow.ExecuteDryRun()
+	assert.True(t, act_assert.IsWorkflowError(err))
+
+	results := act_assert.NewResults(*workflow)
+	test := results.MatrixJob("test").Combination(map[string]any{"os": "linux"})
+	assert.Equal(t, act_assert.Success, test.Step("0").Result())
+	assert.Equal(t, act_assert.Skipped, test.Step("1").Result())
+	assert.Equal(t, act_assert.Failure, results.Job("deploy").Step("0").Result())
+	assert.Equal(t, "Deploy 1.0.0: deploy failed", results.Job("deploy").Logs())
+}
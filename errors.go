@@ -0,0 +1,135 @@
+package act_assert
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wd-hopkins/act/pkg/model"
+	"github.com/wd-hopkins/act/pkg/runner"
+)
+
+// ErrorKind classifies the cause of an ExecutionError.
+type ErrorKind string
+
+const (
+	// InfrastructureError indicates that the workflow could not be run, e.g. the Docker socket could not be
+	// found, an image could not be pulled or the artifact server could not bind to its port.
+	InfrastructureError ErrorKind = "infrastructure"
+	// WorkflowError indicates that the workflow ran, but one or more of its jobs failed.
+	WorkflowError ErrorKind = "workflow"
+	// CancelledError indicates that execution was stopped because the context was cancelled or its deadline exceeded.
+	CancelledError ErrorKind = "cancelled"
+)
+
+// ExecutionError is returned by Execute and ExecuteContext when a workflow does not run to completion successfully.
+type ExecutionError struct {
+	Kind ErrorKind
+	// FailedJobs contains the names of the failed jobs.
+	FailedJobs []string
+	Err        error
+}
+
+func (e *ExecutionError) Error() string {
+	switch e.Kind {
+	case WorkflowError:
+		return fmt.Sprintf("workflow failed, failed jobs: %s: %v", strings.Join(e.FailedJobs, ", "), e.Err)
+	case CancelledError:
+		return fmt.Sprintf("workflow execution cancelled: %v", e.Err)
+	default:
+		return fmt.Sprintf("error running workflow: %v", e.Err)
+	}
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// IsInfrastructureError reports whether err is an ExecutionError caused by the execution environment.
+func IsInfrastructureError(err error) bool {
+	return isErrorKind(err, InfrastructureError)
+}
+
+// IsWorkflowError reports whether err is an ExecutionError caused by failing jobs.
+func IsWorkflowError(err error) bool {
+	return isErrorKind(err, WorkflowError)
+}
+
+// IsCancelledError reports whether err is an ExecutionError caused by cancelling the execution.
+func IsCancelledError(err error) bool {
+	return isErrorKind(err, CancelledError)
+}
+
+func isErrorKind(err error, kind ErrorKind) bool {
+	var executionErr *ExecutionError
+	return errors.As(err, &executionErr) && executionErr.Kind == kind
+}
+
+func infrastructureError(err error) error {
+	return &ExecutionError{Kind: InfrastructureError, Err: err}
+}
+
+// classifyPlanError determines the kind of error returned by the plan executor. A job that failed because one
+// of its steps failed, or because its result was forced by the plan, is a workflow failure. A job that failed
+// otherwise could not set up its containers, which is an infrastructure error. forced contains the IDs of the
+// jobs whose result was forced.
+func classifyPlanError(err error, runContexts []*runner.RunContext, forced map[string]bool) error {
+	var failedJobs []string
+	infrastructureFailed := false
+	var collect func(runContext *runner.RunContext, forced bool)
+	collect = func(runContext *runner.RunContext, forced bool) {
+		if runContext.ChildContexts != nil {
+			for _, childContext := range *runContext.ChildContexts {
+				collect(childContext, forced)
+			}
+			return
+		}
+		job := runContext.Run.Job()
+		if job.Result != string(Failure) {
+			return
+		}
+		name := runContext.JobName
+		if name == "" {
+			name = runContext.Run.JobID
+		}
+		failedJobs = append(failedJobs, name)
+		stepFailed := false
+		for _, step := range job.Steps {
+			stepFailed = stepFailed || step.Result == string(Failure)
+		}
+		infrastructureFailed = infrastructureFailed || !stepFailed && !forced
+	}
+	for _, runContext := range runContexts {
+		collect(runContext, forced[runContext.Run.JobID])
+	}
+
+	if len(failedJobs) == 0 && err == nil {
+		return nil
+	}
+	if err == nil {
+		err = errors.New("one or more jobs failed")
+	}
+	if len(failedJobs) > 0 && !infrastructureFailed {
+		return &ExecutionError{Kind: WorkflowError, FailedJobs: failedJobs, Err: err}
+	}
+	return &ExecutionError{Kind: InfrastructureError, FailedJobs: failedJobs, Err: err}
+}
+
+// forcedJobResults returns the IDs of the jobs of plan whose result, or the result of some of whose matrix
+// combinations, is set before execution, e.g. by JobPlan.SetResult or MatrixJobPlan.SetResult.
+func forcedJobResults(plan *model.Plan, overrides []*matrixOverride) map[string]bool {
+	forced := map[string]bool{}
+	for _, override := range overrides {
+		if override.result != "" && override.result != Skipped {
+			forced[override.jobID] = true
+		}
+	}
+	for _, stage := range plan.Stages {
+		for _, run := range stage.Runs {
+			if run.Job().Result != "" {
+				forced[run.JobID] = true
+			}
+		}
+	}
+	return forced
+}
@@ -0,0 +1,73 @@
+package act_assert_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_execute_without_plan(t *testing.T) {
+	err := act_assert.New().Execute()
+	assert.True(t, act_assert.IsInfrastructureError(err))
+}
+
+func Test_execute_returns_workflow_error(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath(".github/workflows/example.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	err = workflow.Execute()
+	assert.True(t, act_assert.IsWorkflowError(err))
+
+	var executionErr *act_assert.ExecutionError
+	assert.True(t, errors.As(err, &executionErr))
+	assert.Equal(t, []string{"cleanup"}, executionErr.FailedJobs)
+}
+
+func Test_execute_context_cancelled(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/long_running.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err = workflow.ExecuteContext(ctx)
+	assert.True(t, act_assert.IsCancelledError(err))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The container of the cancelled job is torn down
+	docker, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	assert.NoError(t, err)
+	defer docker.Close()
+	containers, err := docker.ContainerList(context.Background(), container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("name", "act-Test-cancelling-execution")),
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, containers)
+}
+
+func Test_execute_forced_failure_is_workflow_error(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath(".github/workflows/example.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("main").SetResult(act_assert.Failure)
+
+	err = workflow.Execute()
+	assert.True(t, act_assert.IsWorkflowError(err))
+
+	var executionErr *act_assert.ExecutionError
+	assert.True(t, errors.As(err, &executionErr))
+	assert.Equal(t, []string{"main"}, executionErr.FailedJobs)
+}
@@ -0,0 +1,307 @@
+package act_assert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EventPayload is the webhook payload of the event that triggers a workflow. It is exposed to workflows
+// through the `github.event` context and determines the ref related GitHub environment variables.
+type EventPayload interface {
+	// EventName returns the name of the event, e.g. push or pull_request.
+	EventName() string
+	// githubEnv returns the GitHub environment variables implied by the payload.
+	githubEnv(defaultBranch string) map[GithubEnv]string
+}
+
+type User struct {
+	Login string `json:"login"`
+	ID    int64  `json:"id,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+type Repository struct {
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	Owner         User   `json:"owner"`
+	DefaultBranch string `json:"default_branch,omitempty"`
+	Private       bool   `json:"private"`
+	Fork          bool   `json:"fork"`
+}
+
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type CommitAuthor struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username,omitempty"`
+}
+
+type Commit struct {
+	ID        string       `json:"id"`
+	Message   string       `json:"message"`
+	Timestamp string       `json:"timestamp,omitempty"`
+	Author    CommitAuthor `json:"author"`
+	Added     []string     `json:"added"`
+	Removed   []string     `json:"removed"`
+	Modified  []string     `json:"modified"`
+}
+
+// PushEvent is the payload of the push event.
+type PushEvent struct {
+	Ref        string       `json:"ref"`
+	Before     string       `json:"before"`
+	After      string       `json:"after"`
+	BaseRef    *string      `json:"base_ref"`
+	Created    bool         `json:"created"`
+	Deleted    bool         `json:"deleted"`
+	Forced     bool         `json:"forced"`
+	Commits    []Commit     `json:"commits"`
+	HeadCommit *Commit      `json:"head_commit"`
+	Pusher     CommitAuthor `json:"pusher"`
+	Repository Repository   `json:"repository"`
+	Sender     User         `json:"sender"`
+}
+
+func (e PushEvent) EventName() string {
+	return "push"
+}
+
+func (e PushEvent) githubEnv(defaultBranch string) map[GithubEnv]string {
+	ref := e.Ref
+	if ref == "" {
+		ref = "refs/heads/" + defaultBranch
+	}
+	return withSha(refEnv(ref), e.After)
+}
+
+type PullRequestRef struct {
+	Label string      `json:"label,omitempty"`
+	Ref   string      `json:"ref"`
+	Sha   string      `json:"sha"`
+	User  User        `json:"user"`
+	Repo  *Repository `json:"repo,omitempty"`
+}
+
+type PullRequest struct {
+	Number int            `json:"number"`
+	Title  string         `json:"title"`
+	Body   string         `json:"body"`
+	State  string         `json:"state"`
+	Draft  bool           `json:"draft"`
+	Merged bool           `json:"merged"`
+	User   User           `json:"user"`
+	Labels []Label        `json:"labels"`
+	Head   PullRequestRef `json:"head"`
+	Base   PullRequestRef `json:"base"`
+}
+
+// PullRequestEvent is the payload of the pull_request event, or the pull_request_target event if Target is set.
+type PullRequestEvent struct {
+	Action      string      `json:"action"`
+	Number      int         `json:"number"`
+	PullRequest PullRequest `json:"pull_request"`
+	// Label is the label that was added or removed, for the labeled and unlabeled actions.
+	Label      *Label     `json:"label,omitempty"`
+	Repository Repository `json:"repository"`
+	Sender     User       `json:"sender"`
+	// Target triggers the pull_request_target event instead of pull_request.
+	Target bool `json:"-"`
+}
+
+func (e PullRequestEvent) EventName() string {
+	if e.Target {
+		return "pull_request_target"
+	}
+	return "pull_request"
+}
+
+func (e PullRequestEvent) githubEnv(defaultBranch string) map[GithubEnv]string {
+	number := e.Number
+	if number == 0 {
+		number = e.PullRequest.Number
+	}
+	var env map[GithubEnv]string
+	if e.Target {
+		base := e.PullRequest.Base.Ref
+		if base == "" {
+			base = defaultBranch
+		}
+		env = withSha(refEnv("refs/heads/"+base), e.PullRequest.Base.Sha)
+	} else {
+		env = withSha(refEnv(fmt.Sprintf("refs/pull/%d/merge", number)), e.PullRequest.Head.Sha)
+	}
+	env[GithubHeadRef] = e.PullRequest.Head.Ref
+	env[GithubBaseRef] = e.PullRequest.Base.Ref
+	return env
+}
+
+type Release struct {
+	ID              int64  `json:"id,omitempty"`
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	Draft           bool   `json:"draft"`
+	Prerelease      bool   `json:"prerelease"`
+	Author          User   `json:"author"`
+}
+
+// ReleaseEvent is the payload of the release event.
+type ReleaseEvent struct {
+	Action     string     `json:"action"`
+	Release    Release    `json:"release"`
+	Repository Repository `json:"repository"`
+	Sender     User       `json:"sender"`
+}
+
+func (e ReleaseEvent) EventName() string {
+	return "release"
+}
+
+func (e ReleaseEvent) githubEnv(string) map[GithubEnv]string {
+	return refEnv("refs/tags/" + e.Release.TagName)
+}
+
+type Issue struct {
+	Number int     `json:"number"`
+	Title  string  `json:"title"`
+	Body   string  `json:"body"`
+	State  string  `json:"state"`
+	User   User    `json:"user"`
+	Labels []Label `json:"labels"`
+	// PullRequest is set when the issue is a pull request.
+	PullRequest *IssuePullRequest `json:"pull_request,omitempty"`
+}
+
+type IssuePullRequest struct {
+	URL     string `json:"url"`
+	HTMLURL string `json:"html_url,omitempty"`
+}
+
+type Comment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+	User User   `json:"user"`
+}
+
+// IssueCommentEvent is the payload of the issue_comment event.
+type IssueCommentEvent struct {
+	Action     string     `json:"action"`
+	Issue      Issue      `json:"issue"`
+	Comment    Comment    `json:"comment"`
+	Repository Repository `json:"repository"`
+	Sender     User       `json:"sender"`
+}
+
+func (e IssueCommentEvent) EventName() string {
+	return "issue_comment"
+}
+
+func (e IssueCommentEvent) githubEnv(defaultBranch string) map[GithubEnv]string {
+	return refEnv("refs/heads/" + defaultBranch)
+}
+
+// ScheduleEvent is the payload of the schedule event.
+type ScheduleEvent struct {
+	// Schedule is the cron expression that triggered the workflow.
+	Schedule string `json:"schedule"`
+}
+
+func (e ScheduleEvent) EventName() string {
+	return "schedule"
+}
+
+func (e ScheduleEvent) githubEnv(defaultBranch string) map[GithubEnv]string {
+	return refEnv("refs/heads/" + defaultBranch)
+}
+
+// WorkflowDispatchEvent is the payload of the workflow_dispatch event. Inputs are also passed to the
+// `inputs` context.
+type WorkflowDispatchEvent struct {
+	Ref        string         `json:"ref"`
+	Inputs     map[string]any `json:"inputs"`
+	Repository Repository     `json:"repository"`
+	Sender     User           `json:"sender"`
+	Workflow   string         `json:"workflow,omitempty"`
+}
+
+func (e WorkflowDispatchEvent) EventName() string {
+	return "workflow_dispatch"
+}
+
+func (e WorkflowDispatchEvent) githubEnv(defaultBranch string) map[GithubEnv]string {
+	if e.Ref == "" {
+		return refEnv("refs/heads/" + defaultBranch)
+	}
+	return refEnv(e.Ref)
+}
+
+// RawEvent is an arbitrary event payload for events without a typed payload.
+type RawEvent struct {
+	Name    string
+	Payload map[string]any
+}
+
+func (e RawEvent) EventName() string {
+	return e.Name
+}
+
+func (e RawEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Payload)
+}
+
+func (e RawEvent) githubEnv(string) map[GithubEnv]string {
+	if ref, ok := e.Payload["ref"].(string); ok && strings.HasPrefix(ref, "refs/") {
+		return refEnv(ref)
+	}
+	return map[GithubEnv]string{}
+}
+
+func refEnv(ref string) map[GithubEnv]string {
+	env := map[GithubEnv]string{
+		GithubRef: ref,
+	}
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		env[GithubRefName] = strings.TrimPrefix(ref, "refs/heads/")
+		env[GithubRefType] = "branch"
+	case strings.HasPrefix(ref, "refs/tags/"):
+		env[GithubRefName] = strings.TrimPrefix(ref, "refs/tags/")
+		env[GithubRefType] = "tag"
+	case strings.HasPrefix(ref, "refs/pull/"):
+		env[GithubRefName] = strings.TrimPrefix(ref, "refs/pull/")
+		env[GithubRefType] = "branch"
+	}
+	return env
+}
+
+func withSha(env map[GithubEnv]string, sha string) map[GithubEnv]string {
+	if sha != "" {
+		env[ShaRef] = sha
+	}
+	return env
+}
+
+// writeEventPayload writes the payload to a temporary file and returns its path.
+func writeEventPayload(payload EventPayload) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling %s event payload: %w", payload.EventName(), err)
+	}
+	file, err := os.CreateTemp("", "act-assert-event-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err := file.Write(body); err != nil {
+		return "", err
+	}
+	return file.Name(), nil
+}
@@ -0,0 +1,87 @@
+package act_assert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_push_event_payload(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/events.yaml").
+		WithEventPayload(act_assert.PushEvent{
+			Ref:   "refs/heads/feature",
+			After: "0123456789abcdef0123456789abcdef01234567",
+		}).
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	assert.Equal(t,
+		"ref=refs/heads/feature sha=0123456789abcdef0123456789abcdef01234567",
+		results.Job("push_job").Step("Print ref").Logs())
+}
+
+func Test_event_payload_ref_env_uses_final_settings(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/events.yaml").
+		WithEventPayload(act_assert.PullRequestEvent{
+			Number:      42,
+			PullRequest: act_assert.PullRequest{Number: 42, Head: act_assert.PullRequestRef{Ref: "feature"}},
+		}).
+		WithEventPayload(act_assert.PushEvent{}).
+		WithDefaultBranch("trunk").
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("push_job")
+	assert.Equal(t, "ref=refs/heads/trunk sha=", job.Step("Print ref").Logs())
+	assert.Equal(t, "head_ref=", job.Step("Print head ref").Logs())
+}
+
+func Test_pull_request_event_payload(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/events.yaml").
+		WithEventPayload(act_assert.PullRequestEvent{
+			Action: "labeled",
+			Number: 42,
+			PullRequest: act_assert.PullRequest{
+				Number: 42,
+				Labels: []act_assert.Label{{Name: "preview"}},
+				Head:   act_assert.PullRequestRef{Ref: "feature"},
+				Base:   act_assert.PullRequestRef{Ref: "main"},
+			},
+		}).
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("pull_request_job")
+	assert.Equal(t, "head=feature base=main", job.Step("Print refs").Logs())
+	assert.Equal(t, act_assert.Success, job.Step("Deploy preview").Result())
+}
+
+func Test_workflow_dispatch_event_payload(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/events.yaml").
+		WithEventPayload(act_assert.WorkflowDispatchEvent{
+			Inputs: map[string]any{"environment": "staging"},
+		}).
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	assert.Equal(t,
+		"environment=staging event=staging",
+		results.Job("dispatch_job").Step("Print input").Logs())
+}
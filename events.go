@@ -0,0 +1,133 @@
+package act_assert
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/wd-hopkins/act/pkg/runner"
+)
+
+// executionEvent is a log entry emitted by the runner while executing the plan.
+type executionEvent struct {
+	time    time.Time
+	level   logrus.Level
+	message string
+	data    logrus.Fields
+}
+
+// jobID returns the ID of the job that emitted the event.
+func (e executionEvent) jobID() string {
+	jobID, _ := e.data["jobID"].(string)
+	return jobID
+}
+
+// stepID returns the ID of the top level step that emitted the event, if any.
+func (e executionEvent) stepID() string {
+	switch stepID := e.data["stepID"].(type) {
+	case string:
+		return stepID
+	case []string:
+		if len(stepID) > 0 {
+			return stepID[0]
+		}
+	}
+	return ""
+}
+
+// stage returns the stage of the step that emitted the event, i.e. Pre, Main or Post.
+func (e executionEvent) stage() string {
+	stage, _ := e.data["stage"].(string)
+	return stage
+}
+
+// belongsTo reports whether the event was emitted by the job, or matrix combination of the job, runContext runs.
+func (e executionEvent) belongsTo(runContext *runner.RunContext) bool {
+	if e.jobID() != runContext.Run.JobID {
+		return false
+	}
+	matrix, _ := e.data["matrix"].(map[string]interface{})
+	return fmt.Sprint(matrix) == fmt.Sprint(runContext.Matrix)
+}
+
+// eventRecorder is a logrus hook recording the log entries of the job loggers.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []executionEvent
+	// listeners are called with each event as it is recorded.
+	listeners []func(executionEvent)
+}
+
+func newEventRecorder() *eventRecorder {
+	return &eventRecorder{}
+}
+
+func (r *eventRecorder) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (r *eventRecorder) Fire(entry *logrus.Entry) error {
+	event := executionEvent{
+		time:    entry.Time,
+		level:   entry.Level,
+		message: entry.Message,
+		data:    maps.Clone(entry.Data),
+	}
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	listeners := r.listeners
+	r.mu.Unlock()
+	for _, listener := range listeners {
+		listener(event)
+	}
+	return nil
+}
+
+// onEvent calls listener with each event recorded from now on, in the goroutine of the job emitting it.
+func (r *eventRecorder) onEvent(listener func(executionEvent)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(slices.Clone(r.listeners), listener)
+}
+
+// jobEvents returns the events emitted by the job, or matrix combination of the job, runContext runs.
+func (r *eventRecorder) jobEvents(runContext *runner.RunContext) []executionEvent {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []executionEvent
+	for _, event := range r.events {
+		if event.belongsTo(runContext) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// jobSpan returns the positions, among all recorded events, of the first event of the job and of the event
+// reporting its result. ok is false if the job did not finish.
+func (r *eventRecorder) jobSpan(runContext *runner.RunContext) (start, end int, ok bool) {
+	if r == nil {
+		return 0, 0, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	start = -1
+	for i, event := range r.events {
+		if !event.belongsTo(runContext) {
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		if _, finished := event.data["jobResult"]; finished {
+			end, ok = i, true
+		}
+	}
+	return start, end, ok
+}
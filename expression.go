@@ -0,0 +1,463 @@
+package act_assert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+	"github.com/wd-hopkins/act/pkg/exprparser"
+	"github.com/wd-hopkins/act/pkg/model"
+	"github.com/wd-hopkins/act/pkg/runner"
+	"gopkg.in/yaml.v3"
+)
+
+// expressionEvaluator evaluates the expressions of a job or step against the contexts available to it.
+type expressionEvaluator struct {
+	env    *exprparser.EvaluationEnvironment
+	config exprparser.Config
+}
+
+func (e *expressionEvaluator) evaluate(expr string, statusCheck exprparser.DefaultStatusCheck) (any, error) {
+	return exprparser.NewInterpeter(e.env, e.config).Evaluate(expr, statusCheck)
+}
+
+// evaluateCondition evaluates an `if:` condition, which defaults to success() when empty.
+func (e *expressionEvaluator) evaluateCondition(condition string) (bool, error) {
+	condition = strings.TrimSpace(condition)
+	if condition == "" {
+		condition = "success()"
+	}
+	if expr, ok := wholeExpression(condition); ok {
+		condition = expr
+	} else if strings.Contains(condition, "${{") {
+		interpolated, err := e.interpolate(condition)
+		return err == nil && exprparser.IsTruthy(interpolated), err
+	}
+	evaluated, err := e.evaluate(condition, exprparser.DefaultStatusCheckSuccess)
+	if err != nil {
+		return false, fmt.Errorf("evaluating condition '%s': %w", condition, err)
+	}
+	return exprparser.IsTruthy(evaluated), nil
+}
+
+var expressionRegex = regexp.MustCompile(`(?s)\$\{\{\s*(.*?)\s*\}\}`)
+
+// wholeExpression returns the expression s consists of, if s is a single `${{ }}` expression.
+func wholeExpression(s string) (string, bool) {
+	match := expressionRegex.FindStringSubmatchIndex(s)
+	if match == nil || match[0] != 0 || match[1] != len(s) {
+		return "", false
+	}
+	return s[match[2]:match[3]], true
+}
+
+// interpolate replaces the `${{ }}` expressions in s with their values.
+func (e *expressionEvaluator) interpolate(s string) (string, error) {
+	var err error
+	interpolated := expressionRegex.ReplaceAllStringFunc(s, func(match string) string {
+		if err != nil {
+			return ""
+		}
+		expr := expressionRegex.FindStringSubmatch(match)[1]
+		value, evalErr := e.evaluate(expr, exprparser.DefaultStatusCheckNone)
+		if evalErr != nil {
+			err = fmt.Errorf("evaluating expression '%s': %w", expr, evalErr)
+			return ""
+		}
+		return formatValue(value)
+	})
+	return interpolated, err
+}
+
+// interpolateMap interpolates the values of m into a new map.
+func (e *expressionEvaluator) interpolateMap(m map[string]string) (map[string]string, error) {
+	interpolated := make(map[string]string, len(m))
+	for k, v := range m {
+		value, err := e.interpolate(v)
+		if err != nil {
+			return nil, err
+		}
+		interpolated[k] = value
+	}
+	return interpolated, nil
+}
+
+// evaluateYamlNode replaces the expressions in the scalars of node with their values. A scalar consisting of
+// a single expression is replaced with the value itself, so that e.g. fromJSON can produce a sequence.
+func (e *expressionEvaluator) evaluateYamlNode(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if expr, ok := wholeExpression(node.Value); ok {
+			value, err := e.evaluate(expr, exprparser.DefaultStatusCheckNone)
+			if err != nil {
+				return fmt.Errorf("evaluating expression '%s': %w", expr, err)
+			}
+			var evaluated yaml.Node
+			if err := evaluated.Encode(value); err != nil {
+				return err
+			}
+			*node = evaluated
+			return nil
+		}
+		interpolated, err := e.interpolate(node.Value)
+		node.Value = interpolated
+		return err
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := e.evaluateYamlNode(node.Content[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, child := range node.Content {
+			if err := e.evaluateYamlNode(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyYamlNode returns a deep copy of node.
+func copyYamlNode(node *yaml.Node) *yaml.Node {
+	clone := *node
+	clone.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		clone.Content[i] = copyYamlNode(child)
+	}
+	return &clone
+}
+
+// formatValue formats an evaluated expression the way it is interpolated into a string.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
+
+// githubContext returns the `github` context of the job runContext runs, as the runner derives it.
+func githubContext(runContext *runner.RunContext) (*model.GithubContext, error) {
+	evaluated := runContext.NewExpressionEvaluator(context.Background()).
+		Interpolate(context.Background(), "${{ toJSON(github) }}")
+	var github model.GithubContext
+	if err := json.Unmarshal([]byte(evaluated), &github); err != nil {
+		return nil, fmt.Errorf("evaluating the github context: %w", err)
+	}
+	return &github, nil
+}
+
+// eventContext returns the event payload as the `github.event` context.
+func (a *ActAssert) eventContext() (map[string]any, error) {
+	var payload []byte
+	var err error
+	switch {
+	case a.eventPayload != nil:
+		payload, err = json.Marshal(a.eventPayload)
+	case a.eventPath != "":
+		payload, err = os.ReadFile(a.eventPath)
+	default:
+		return map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	event := map[string]any{}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("reading event payload: %w", err)
+	}
+	return event, nil
+}
+
+// jobEvaluator returns an evaluator for the expressions of the job, or matrix combination of the job,
+// runContext runs. strategy is the `strategy` context of the combination.
+func (a *ActAssert) jobEvaluator(runContext *runner.RunContext, strategy map[string]any) (*expressionEvaluator, error) {
+	run := runContext.Run
+	github, err := githubContext(runContext)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := maps.Clone(a.secrets)
+	if secrets == nil {
+		secrets = map[string]string{}
+	}
+	if _, ok := secrets["GITHUB_TOKEN"]; !ok && a.token != "" {
+		secrets["GITHUB_TOKEN"] = a.token
+	}
+	vars := maps.Clone(a.vars)
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	inputs := make(map[string]any, len(a.inputs))
+	for k, v := range a.inputs {
+		inputs[k] = v
+	}
+	matrix := runContext.Matrix
+	if matrix == nil {
+		matrix = map[string]any{}
+	}
+
+	needs := map[string]exprparser.Needs{}
+	for _, need := range run.Job().Needs() {
+		if job := run.Workflow.GetJob(need); job != nil {
+			needs[need] = exprparser.Needs{Outputs: maps.Clone(job.Outputs), Result: job.Result}
+		}
+	}
+
+	env := maps.Clone(run.Workflow.Env)
+	if env == nil {
+		env = map[string]string{}
+	}
+
+	workdir, err := filepath.Abs(a.workdir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &expressionEvaluator{
+		env: &exprparser.EvaluationEnvironment{
+			Github:   github,
+			Env:      env,
+			Job:      &model.JobContext{Status: string(Success)},
+			Steps:    map[string]*model.StepResult{},
+			Runner:   map[string]any{"os": "Linux", "arch": "X64", "name": "act-assert", "temp": "/tmp", "tool_cache": "/opt/hostedtoolcache"},
+			Secrets:  secrets,
+			Vars:     vars,
+			Strategy: strategy,
+			Matrix:   matrix,
+			Needs:    needs,
+			Inputs:   inputs,
+		},
+		config: exprparser.Config{Run: run, WorkingDir: workdir, Context: "job"},
+	}, nil
+}
+
+// ExpressionContext contains the contexts available to an expression evaluated with EvaluateExpression.
+type ExpressionContext struct {
+	// EventName is the name of the event that triggered the workflow, i.e. `github.event_name`.
+	EventName string
+	// Event is the webhook payload of the event, i.e. `github.event`.
+	Event map[string]any
+	// GithubEnv sets the other fields of the `github` context the way ActAssert.WithEnvironment does, e.g.
+	// GithubRef sets `github.ref`.
+	GithubEnv map[GithubEnv]string
+	Env       map[string]string
+	Vars      map[string]string
+	Secrets   map[string]string
+	Inputs    map[string]string
+	Needs     map[string]NeedsContext
+	Steps     map[string]StepContext
+	Matrix    map[string]any
+	// Strategy is the `strategy` context of the matrix combination. Empty if nil, as for a job without a
+	// strategy.
+	Strategy *StrategyContext
+	// WorkingDir is the directory hashFiles matches files in. Defaults to the current directory.
+	WorkingDir string
+}
+
+// NeedsContext is the `needs.<job_id>` context of a job the evaluated job depends on.
+type NeedsContext struct {
+	Result  Result
+	Outputs map[string]string
+}
+
+// StrategyContext is the `strategy` context of a matrix combination of the evaluated job.
+type StrategyContext struct {
+	FailFast    bool
+	JobIndex    int
+	JobTotal    int
+	MaxParallel int
+}
+
+// StepContext is the `steps.<step_id>` context of a step that ran before the evaluated step.
+type StepContext struct {
+	Outcome    Result
+	Conclusion Result
+	Outputs    map[string]string
+}
+
+// expressionJobID is the ID of the job expressions evaluated with EvaluateExpression belong to.
+const expressionJobID = "expression"
+
+// EvaluateExpression evaluates expr against ctx with the expression evaluator of the runner, as the
+// expressions of a job are evaluated at run time. expr is either a bare expression, e.g.
+// `contains(github.event.pull_request.labels.*.name, 'preview')`, a single `${{ }}` expression, or a string
+// with embedded `${{ }}` expressions, in which case the interpolated string is returned. Status functions,
+// such as success(), check the results of ctx.Needs, as in the `if:` condition of a job.
+func EvaluateExpression(expr string, ctx ExpressionContext) (any, error) {
+	runContext, err := newExpressionRunContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: expr}
+	if !strings.Contains(expr, "${{") {
+		node.Value = fmt.Sprintf("${{ %s }}", expr)
+	}
+	if ctx.Strategy != nil {
+		node.Value = substituteJobIndex(node.Value, *ctx.Strategy)
+	}
+	evaluator := runContext.NewExpressionEvaluatorWithEnv(context.Background(), nonNilMap(ctx.Env))
+	if err := evaluator.EvaluateYamlNode(context.Background(), node); err != nil {
+		return nil, fmt.Errorf("evaluating expression '%s': %w", expr, err)
+	}
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// newExpressionRunContext returns a run context of a job with the contexts of ctx.
+func newExpressionRunContext(ctx ExpressionContext) (*runner.RunContext, error) {
+	workdir := ctx.WorkingDir
+	if workdir == "" {
+		workdir = "."
+	}
+	workdir, err := filepath.Abs(workdir)
+	if err != nil {
+		return nil, err
+	}
+	event, err := json.Marshal(nonNilMap(ctx.Event))
+	if err != nil {
+		return nil, err
+	}
+	githubEnv := make(map[string]string, len(ctx.GithubEnv))
+	for k, v := range ctx.GithubEnv {
+		githubEnv[string(k)] = v
+	}
+	// The runner reads the `inputs` context from the INPUT_ variables of the job environment
+	env := make(map[string]string, len(ctx.Inputs))
+	for k, v := range ctx.Inputs {
+		env["INPUT_"+strings.ToUpper(k)] = v
+	}
+
+	job := &model.Job{}
+	workflow := &model.Workflow{Jobs: map[string]*model.Job{expressionJobID: job}}
+	needs := slices.Sorted(maps.Keys(ctx.Needs))
+	for _, id := range needs {
+		workflow.Jobs[id] = &model.Job{Result: string(ctx.Needs[id].Result), Outputs: ctx.Needs[id].Outputs}
+	}
+	if err := job.RawNeeds.Encode(needs); err != nil {
+		return nil, err
+	}
+	// The runner derives fail-fast and max-parallel of the `strategy` context from the job
+	if strategy := ctx.Strategy; strategy != nil {
+		job.Strategy = &model.Strategy{
+			FailFast:          strategy.FailFast,
+			MaxParallel:       strategy.MaxParallel,
+			FailFastString:    strconv.FormatBool(strategy.FailFast),
+			MaxParallelString: strconv.Itoa(strategy.MaxParallel),
+		}
+	}
+
+	steps := make(map[string]*model.StepResult, len(ctx.Steps))
+	for id, step := range ctx.Steps {
+		outcome, conclusion := step.Outcome, step.Conclusion
+		if outcome == "" {
+			outcome = Success
+		}
+		if conclusion == "" {
+			conclusion = outcome
+		}
+		steps[id] = newStepResult(outcome, conclusion, step.Outputs)
+	}
+
+	return &runner.RunContext{
+		Name:    expressionJobID,
+		JobName: expressionJobID,
+		Config: &runner.Config{
+			Workdir:   workdir,
+			EventName: ctx.EventName,
+			Env:       githubEnv,
+			Secrets:   nonNilMap(ctx.Secrets),
+			Vars:      nonNilMap(ctx.Vars),
+		},
+		Run:         &model.Run{Workflow: workflow, JobID: expressionJobID},
+		Matrix:      nonNilMap(ctx.Matrix),
+		EventJSON:   string(event),
+		Env:         env,
+		StepResults: steps,
+	}, nil
+}
+
+// substituteJobIndex replaces `strategy.job-index` and `strategy.job-total` in the expressions of s with the
+// values of strategy, which the runner sets per matrix combination rather than deriving them from the job.
+func substituteJobIndex(s string, strategy StrategyContext) string {
+	values := map[string]int{"job-index": strategy.JobIndex, "job-total": strategy.JobTotal}
+	return expressionRegex.ReplaceAllStringFunc(s, func(match string) string {
+		expr := expressionRegex.FindStringSubmatch(match)[1]
+		var tokens []*actionlint.Token
+		lexer := actionlint.NewExprLexer(expr + "}}")
+		for {
+			token := lexer.Next()
+			if token.Kind == actionlint.TokenKindUnknown {
+				// Left for the runner to report
+				return match
+			}
+			if token.Kind == actionlint.TokenKindEnd {
+				break
+			}
+			tokens = append(tokens, token)
+		}
+
+		var substituted strings.Builder
+		offset := 0
+		for i := 0; i < len(tokens); i++ {
+			if tokens[i].Kind != actionlint.TokenKindIdent || !strings.EqualFold(tokens[i].Value, "strategy") {
+				continue
+			}
+			var property *actionlint.Token
+			var last int
+			switch {
+			case i+2 < len(tokens) && tokens[i+1].Kind == actionlint.TokenKindDot &&
+				tokens[i+2].Kind == actionlint.TokenKindIdent:
+				property, last = tokens[i+2], i+2
+			case i+3 < len(tokens) && tokens[i+1].Kind == actionlint.TokenKindLeftBracket &&
+				tokens[i+2].Kind == actionlint.TokenKindString && tokens[i+3].Kind == actionlint.TokenKindRightBracket:
+				property, last = tokens[i+2], i+3
+			default:
+				continue
+			}
+			value, ok := values[strings.ToLower(strings.Trim(property.Value, "'"))]
+			if !ok {
+				continue
+			}
+			substituted.WriteString(expr[offset:tokens[i].Offset])
+			substituted.WriteString(strconv.Itoa(value))
+			offset = tokens[last].Offset + len(tokens[last].Value)
+			i = last
+		}
+		substituted.WriteString(expr[offset:])
+		return fmt.Sprintf("${{ %s }}", substituted.String())
+	})
+}
+
+func nonNilMap[V any](m map[string]V) map[string]V {
+	if m == nil {
+		return map[string]V{}
+	}
+	return m
+}
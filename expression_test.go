@@ -0,0 +1,79 @@
+package act_assert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_evaluate_expression(t *testing.T) {
+	ctx := act_assert.ExpressionContext{
+		EventName: "pull_request",
+		Event: map[string]any{
+			"pull_request": map[string]any{
+				"labels": []any{
+					map[string]any{"name": "bug"},
+					map[string]any{"name": "preview"},
+				},
+			},
+		},
+		GithubEnv: map[act_assert.GithubEnv]string{act_assert.GithubRef: "refs/pull/1/merge"},
+		Inputs:    map[string]string{"environment": "staging"},
+		Needs: map[string]act_assert.NeedsContext{
+			"build": {Result: act_assert.Success, Outputs: map[string]string{"targets": `["linux","windows"]`}},
+		},
+		Steps: map[string]act_assert.StepContext{
+			"lint": {Outcome: act_assert.Failure, Conclusion: act_assert.Success},
+		},
+	}
+
+	tests := []struct {
+		expr     string
+		expected any
+	}{
+		{"contains(github.event.pull_request.labels.*.name, 'preview')", true},
+		{"${{ case(inputs.environment == 'production', 'prod', 'non-prod') }}", "non-prod"},
+		{"fromJSON(needs.build.outputs.targets)[1]", "windows"},
+		{"steps.lint.outcome == 'failure' && success()", true},
+		{"deploying to ${{ inputs.environment }} from ${{ github.ref }}", "deploying to staging from refs/pull/1/merge"},
+		{"hashFiles('test/does-not-exist/**')", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			value, err := act_assert.EvaluateExpression(tt.expr, ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func Test_evaluate_invalid_expression(t *testing.T) {
+	_, err := act_assert.EvaluateExpression("github.ref ==", act_assert.ExpressionContext{})
+	assert.Error(t, err)
+}
+
+func Test_evaluate_expression_strategy(t *testing.T) {
+	ctx := act_assert.ExpressionContext{
+		Matrix:   map[string]any{"os": "ubuntu-latest"},
+		Strategy: &act_assert.StrategyContext{FailFast: true, JobIndex: 1, JobTotal: 3, MaxParallel: 2},
+	}
+
+	tests := []struct {
+		expr     string
+		expected any
+	}{
+		{"strategy.job-index", 1},
+		{"strategy['job-total'] - strategy.job-index", 2},
+		{"strategy.fail-fast && strategy.max-parallel == 2", true},
+		{"${{ matrix.os }} leg ${{ strategy.job-index }} of ${{ strategy.job-total }}", "ubuntu-latest leg 1 of 3"},
+		{"format('{0}', 'strategy.job-index')", "strategy.job-index"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			value, err := act_assert.EvaluateExpression(tt.expr, ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package act_assert
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GitHubAPI is a fake GitHub REST and GraphQL API, serving canned responses and logging the requests it
+// receives. Pass it to ActAssert.WithGitHubAPI to point the GitHub URLs of the workflow at it.
+type GitHubAPI struct {
+	mu       sync.Mutex
+	routes   []apiRoute
+	requests []APIRequest
+	server   *httptest.Server
+}
+
+type apiRoute struct {
+	method  string
+	path    string
+	query   string
+	handler http.HandlerFunc
+}
+
+// APIRequest is a request received by a GitHubAPI.
+type APIRequest struct {
+	Method string
+	// Path is the path of the request, relative to the API URL.
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+	Time   time.Time
+}
+
+// JSON decodes the body of the request into v.
+func (r APIRequest) JSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// NewGitHubAPI returns a fake GitHub API without routes, responding 404 Not Found to every request.
+func NewGitHubAPI() *GitHubAPI {
+	return &GitHubAPI{}
+}
+
+// Handle responds to requests matching method and path with status and body, encoded as JSON unless it is
+// already a string or []byte. Segments of path in braces, e.g. /repos/{owner}/{repo}/pulls, match any value,
+// and method "*" matches any method. Routes registered later take precedence.
+func (g *GitHubAPI) Handle(method, path string, status int, body any) *GitHubAPI {
+	return g.HandleFunc(method, path, cannedResponse(status, body))
+}
+
+// HandleFunc handles requests matching method and path with handler, see Handle.
+func (g *GitHubAPI) HandleFunc(method, path string, handler http.HandlerFunc) *GitHubAPI {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.routes = append(g.routes, apiRoute{method: method, path: path, handler: handler})
+	return g
+}
+
+// HandleGraphQL responds to GraphQL requests whose query contains query with status 200 and body, e.g. the
+// name of the operation. Routes registered later take precedence.
+func (g *GitHubAPI) HandleGraphQL(query string, body any) *GitHubAPI {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.routes = append(g.routes, apiRoute{method: http.MethodPost, path: "/graphql", query: query,
+		handler: cannedResponse(http.StatusOK, body)})
+	return g
+}
+
+// Requests returns the requests received during the last execution of a workflow using the API, in order.
+func (g *GitHubAPI) Requests() []APIRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.requests)
+}
+
+// RequestsTo returns the requests received matching method and path, in order, see Handle.
+func (g *GitHubAPI) RequestsTo(method, path string) []APIRequest {
+	var requests []APIRequest
+	for _, request := range g.Requests() {
+		if matchesRoute(method, path, request.Method, request.Path) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// URL returns the URL of the API while the workflow is executed, empty otherwise.
+func (g *GitHubAPI) URL() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.server == nil {
+		return ""
+	}
+	return g.server.URL
+}
+
+// ServeHTTP logs the request and responds with the most recently registered matching route.
+func (g *GitHubAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	path := "/" + strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/v3"), "/")
+	if path == "/api/graphql" {
+		path = "/graphql"
+	}
+
+	g.mu.Lock()
+	g.requests = append(g.requests, APIRequest{
+		Method: r.Method,
+		Path:   path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+		Time:   time.Now(),
+	})
+	var handler http.HandlerFunc
+	for i := len(g.routes) - 1; i >= 0 && handler == nil; i-- {
+		route := g.routes[i]
+		if !matchesRoute(route.method, route.path, r.Method, path) {
+			continue
+		}
+		if route.query != "" {
+			var request struct {
+				Query string `json:"query"`
+			}
+			if json.Unmarshal(body, &request) != nil || !strings.Contains(request.Query, route.query) {
+				continue
+			}
+		}
+		handler = route.handler
+	}
+	g.mu.Unlock()
+
+	if handler == nil {
+		handler = cannedResponse(http.StatusNotFound, map[string]string{
+			"message":           "Not Found",
+			"documentation_url": "https://docs.github.com/rest",
+		})
+	}
+	handler(w, r)
+}
+
+// start serves the API, reachable from job containers at addr, clearing the requests of previous executions.
+// The API serves one execution at a time.
+func (g *GitHubAPI) start(addr string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.server != nil {
+		return errors.New("already serving another execution, e.g. of a copy of the workflow")
+	}
+	listener, baseURL, err := listen(addr)
+	if err != nil {
+		return err
+	}
+	server := httptest.NewUnstartedServer(g)
+	server.Listener = listener
+	server.Start()
+	server.URL = baseURL
+	g.server = server
+	g.requests = nil
+	return nil
+}
+
+func (g *GitHubAPI) stop() {
+	g.mu.Lock()
+	server := g.server
+	g.server = nil
+	g.mu.Unlock()
+	if server != nil {
+		server.Close()
+	}
+}
+
+// env returns the environment pointing the GitHub URLs of the workflow at the API.
+func (g *GitHubAPI) env() map[GithubEnv]string {
+	baseURL := g.URL()
+	return map[GithubEnv]string{
+		GithubServerUrl:  baseURL,
+		GithubApiUrl:     baseURL,
+		GithubGraphqlUrl: baseURL + "/graphql",
+	}
+}
+
+// WithGitHubAPI starts api while the workflow is executed, pointing GITHUB_API_URL, GITHUB_GRAPHQL_URL and
+// GITHUB_SERVER_URL at it. The API is shared with copies of the workflow, which cannot be executed at the same
+// time: executing a workflow while another one is using the API returns an infrastructure error.
+func (a *ActAssert) WithGitHubAPI(api *GitHubAPI) *ActAssert {
+	a.gitHubAPI = api
+	return a
+}
+
+// matchesRoute reports whether the request with method and path matches the route with routeMethod and
+// routePath.
+func matchesRoute(routeMethod, routePath, method, path string) bool {
+	if routeMethod != "*" && !strings.EqualFold(routeMethod, method) {
+		return false
+	}
+	routeSegments := strings.Split(strings.Trim(routePath, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(routeSegments) != len(segments) {
+		return false
+	}
+	for i, segment := range routeSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			continue
+		}
+		if segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func cannedResponse(status int, body any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var b []byte
+		switch body := body.(type) {
+		case nil:
+		case string:
+			b = []byte(body)
+		case []byte:
+			b = body
+		default:
+			var err error
+			if b, err = json.Marshal(body); err != nil {
+				http.Error(w, fmt.Sprintf("encoding canned response: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+		if b != nil {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write(b)
+	}
+}
@@ -0,0 +1,87 @@
+package act_assert_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_github_api_routes(t *testing.T) {
+	api := act_assert.NewGitHubAPI().
+		Handle("GET", "/repos/{owner}/{repo}", http.StatusOK, map[string]any{"default_branch": "main"}).
+		Handle("GET", "/repos/owner/private", http.StatusForbidden, `{"message":"Forbidden"}`).
+		HandleGraphQL("query PullRequest", map[string]any{"data": map[string]any{"repository": nil}})
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	status, body := get("/repos/owner/repo")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"default_branch":"main"}`, body)
+	status, _ = get("/repos/owner/private")
+	assert.Equal(t, http.StatusForbidden, status)
+	status, body = get("/user")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Contains(t, body, "Not Found")
+
+	resp, err := http.Post(server.URL+"/graphql", "application/json",
+		strings.NewReader(`{"query":"query PullRequest { repository { id } }"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	assert.Len(t, api.Requests(), 4)
+	assert.Len(t, api.RequestsTo("GET", "/repos/{owner}/{repo}"), 2)
+	graphQL := api.RequestsTo("POST", "/graphql")
+	if assert.Len(t, graphQL, 1) {
+		var request struct {
+			Query string `json:"query"`
+		}
+		assert.NoError(t, graphQL[0].JSON(&request))
+		assert.Contains(t, request.Query, "PullRequest")
+	}
+}
+
+func Test_github_api(t *testing.T) {
+	api := act_assert.NewGitHubAPI().
+		Handle("GET", "/repos/{owner}/{repo}", http.StatusOK, map[string]any{"default_branch": "trunk"}).
+		Handle("POST", "/repos/{owner}/{repo}/issues/{number}/comments", http.StatusCreated, map[string]any{"id": 1})
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/github_api.yaml").
+		WithGitHubAPI(api).
+		Plan()
+	assert.NoError(t, err)
+
+	err = workflow.Execute()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("review")
+	assert.Equal(t, act_assert.Success, job.Result())
+	assert.Equal(t, "trunk", job.Step("Get repository").Logs())
+
+	comments := api.RequestsTo("POST", "/repos/owner/repo/issues/1/comments")
+	if assert.Len(t, comments, 1) {
+		var comment struct {
+			Body string `json:"body"`
+		}
+		assert.NoError(t, comments[0].JSON(&comment))
+		assert.Equal(t, "Looks good", comment.Body)
+	}
+
+	// The requests are those of the last execution
+	err = workflow.Copy().Execute()
+	assert.NoError(t, err)
+	assert.Len(t, api.RequestsTo("POST", "/repos/owner/repo/issues/1/comments"), 1)
+}
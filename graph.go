@@ -0,0 +1,214 @@
+package act_assert
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/wd-hopkins/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Permissions are the permissions granted to the GITHUB_TOKEN, by scope. The read-all and write-all shorthands
+// are represented by the `*` scope.
+type Permissions map[string]string
+
+// Concurrency is the concurrency group of a job or workflow.
+type Concurrency struct {
+	Group string
+	// CancelInProgress is the cancel-in-progress setting, which may be an expression.
+	CancelInProgress string
+}
+
+// rawWorkflow contains the parts of a workflow file not available from the act model.
+type rawWorkflow struct {
+	Permissions yaml.Node `yaml:"permissions"`
+	Concurrency yaml.Node `yaml:"concurrency"`
+	Jobs        map[string]struct {
+		Permissions yaml.Node `yaml:"permissions"`
+		Concurrency yaml.Node `yaml:"concurrency"`
+	} `yaml:"jobs"`
+}
+
+// workflowFile returns the path of the file the workflow was read from.
+func (a *ActAssert) workflowFile(workflow *model.Workflow) string {
+	if info, err := os.Stat(a.workflowFilePath); err == nil && !info.IsDir() {
+		return a.workflowFilePath
+	}
+	return filepath.Join(a.workflowFilePath, workflow.File)
+}
+
+// JobNode is a read-only view of a job in the job graph of the plan. A JobPlan has the same methods.
+type JobNode struct {
+	jobRun   *model.Run
+	workflow *rawWorkflow
+}
+
+func (a *ActAssert) newJobNode(run *model.Run) *JobNode {
+	return &JobNode{
+		jobRun:   run,
+		workflow: a.workflows[a.workflowFile(run.Workflow)],
+	}
+}
+
+// readWorkflows parses the workflow files of the jobs of plan for the parts not available from the act model.
+func (a *ActAssert) readWorkflows(plan *model.Plan) (map[string]*rawWorkflow, error) {
+	workflows := map[string]*rawWorkflow{}
+	for _, stage := range plan.Stages {
+		for _, run := range stage.Runs {
+			file := a.workflowFile(run.Workflow)
+			if _, ok := workflows[file]; ok {
+				continue
+			}
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			var raw rawWorkflow
+			if err := yaml.Unmarshal(content, &raw); err != nil {
+				return nil, fmt.Errorf("workflow '%s': %w", file, err)
+			}
+			workflows[file] = &raw
+		}
+	}
+	return workflows, nil
+}
+
+// ID returns the ID of the job.
+func (j *JobNode) ID() string {
+	return j.jobRun.JobID
+}
+
+// Needs returns the IDs of the jobs the job directly depends on.
+func (j *JobNode) Needs() []string {
+	return j.jobRun.Job().Needs()
+}
+
+// DependsOn reports whether the job depends on the job with the given ID, directly or transitively.
+func (j *JobNode) DependsOn(jobID string) bool {
+	return dependsOn(j.jobRun.Workflow, j.jobRun.Job(), jobID)
+}
+
+// dependsOn reports whether job depends on the job of workflow with the given ID, directly or transitively.
+func dependsOn(workflow *model.Workflow, job *model.Job, jobID string) bool {
+	visited := map[string]bool{}
+	var visit func(job *model.Job) bool
+	visit = func(job *model.Job) bool {
+		for _, need := range job.Needs() {
+			if need == jobID {
+				return true
+			}
+			if visited[need] {
+				continue
+			}
+			visited[need] = true
+			if neededJob, ok := workflow.Jobs[need]; ok && visit(neededJob) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(job)
+}
+
+// RunsOn returns the runner labels of the job.
+func (j *JobNode) RunsOn() []string {
+	return j.jobRun.Job().RunsOn()
+}
+
+// If returns the `if` condition of the job, or an empty string if it has none.
+func (j *JobNode) If() string {
+	return j.jobRun.Job().If.Value
+}
+
+// TimeoutMinutes returns the timeout-minutes of the job, which may be an expression, or an empty string if not set.
+func (j *JobNode) TimeoutMinutes() string {
+	return j.jobRun.Job().TimeoutMinutes
+}
+
+// Outputs returns the declared outputs of the job, mapping each output to its expression.
+func (j *JobNode) Outputs() map[string]string {
+	return maps.Clone(j.jobRun.Job().Outputs)
+}
+
+// Steps returns the ID of each step, along with its name if it has one.
+func (j *JobNode) Steps() []string {
+	return stepNames(j.jobRun.Job().Steps)
+}
+
+// IsReusableWorkflowCall reports whether the job calls a reusable workflow.
+func (j *JobNode) IsReusableWorkflowCall() bool {
+	jobType, _ := j.jobRun.Job().Type()
+	return jobType == model.JobTypeReusableWorkflowLocal || jobType == model.JobTypeReusableWorkflowRemote
+}
+
+// Permissions returns the GITHUB_TOKEN permissions of the job, falling back to the permissions of the
+// workflow. Returns nil if neither sets permissions.
+func (j *JobNode) Permissions() Permissions {
+	raw := j.workflow
+	if raw == nil {
+		return nil
+	}
+	if job, ok := raw.Jobs[j.jobRun.JobID]; ok && !job.Permissions.IsZero() {
+		return decodePermissions(&job.Permissions)
+	}
+	return decodePermissions(&raw.Permissions)
+}
+
+// Concurrency returns the concurrency group of the job, falling back to that of the workflow. Returns nil if
+// neither sets a concurrency group.
+func (j *JobNode) Concurrency() *Concurrency {
+	raw := j.workflow
+	if raw == nil {
+		return nil
+	}
+	if job, ok := raw.Jobs[j.jobRun.JobID]; ok && !job.Concurrency.IsZero() {
+		return decodeConcurrency(&job.Concurrency)
+	}
+	return decodeConcurrency(&raw.Concurrency)
+}
+
+func decodePermissions(node *yaml.Node) Permissions {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		switch node.Value {
+		case "read-all":
+			return Permissions{"*": "read"}
+		case "write-all":
+			return Permissions{"*": "write"}
+		}
+		return Permissions{}
+	case yaml.MappingNode:
+		permissions := Permissions{}
+		_ = node.Decode(&permissions)
+		return permissions
+	}
+	return nil
+}
+
+func decodeConcurrency(node *yaml.Node) *Concurrency {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return &Concurrency{Group: node.Value}
+	case yaml.MappingNode:
+		var concurrency struct {
+			Group            string `yaml:"group"`
+			CancelInProgress string `yaml:"cancel-in-progress"`
+		}
+		_ = node.Decode(&concurrency)
+		return &Concurrency{Group: concurrency.Group, CancelInProgress: concurrency.CancelInProgress}
+	}
+	return nil
+}
+
+// JobIDs returns the IDs of the jobs.
+func JobIDs(jobs []*JobNode) []string {
+	var ids []string
+	for _, job := range jobs {
+		ids = append(ids, job.ID())
+	}
+	slices.Sort(ids)
+	return ids
+}
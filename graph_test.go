@@ -0,0 +1,55 @@
+package act_assert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_job_graph_stages(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/graph.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	stages := workflow.RequireStages(t)
+	if assert.Len(t, stages, 3) {
+		assert.Equal(t, []string{"lint", "test"}, act_assert.JobIDs(stages[0]))
+		assert.Equal(t, []string{"deploy"}, act_assert.JobIDs(stages[1]))
+		assert.Equal(t, []string{"notify"}, act_assert.JobIDs(stages[2]))
+	}
+
+	_, err = act_assert.New().LookupStages()
+	assert.Error(t, err)
+
+	notify := workflow.Job("notify")
+	assert.Equal(t, []string{"deploy"}, notify.Needs())
+	assert.True(t, notify.DependsOn("test"))
+	assert.False(t, workflow.Job("test").DependsOn("lint"))
+}
+
+func Test_job_graph_properties(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/graph.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	test := workflow.Job("test")
+	assert.Equal(t, []string{"self-hosted", "linux"}, test.RunsOn())
+	assert.Equal(t, "30", test.TimeoutMinutes())
+	assert.Equal(t, map[string]string{"coverage": "${{ steps.coverage.outputs.percent }}"}, test.Outputs())
+	assert.Equal(t, act_assert.Permissions{"contents": "read"}, test.Permissions())
+	assert.Equal(t, &act_assert.Concurrency{Group: "deploy-${{ github.ref }}"}, test.Concurrency())
+
+	deploy := workflow.Job("deploy")
+	assert.Equal(t, "github.ref == 'refs/heads/main'", deploy.If())
+	assert.Equal(t, act_assert.Permissions{"*": "write"}, deploy.Permissions())
+	assert.Equal(t, &act_assert.Concurrency{Group: "production", CancelInProgress: "false"}, deploy.Concurrency())
+
+	for _, job := range workflow.AllJobs() {
+		if job.ID() != "test" {
+			assert.NotContains(t, job.RunsOn(), "self-hosted", "job %s runs on self-hosted", job.ID())
+		}
+	}
+}
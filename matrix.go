@@ -0,0 +1,560 @@
+package act_assert
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/wd-hopkins/act/pkg/model"
+	"github.com/wd-hopkins/act/pkg/runner"
+	"gopkg.in/yaml.v3"
+)
+
+// WithMatrix restricts the matrix combinations that run. Only combinations whose value for each key is one of
+// the allowed values run, e.g. {"os": {"ubuntu-latest": true}} runs only the ubuntu-latest combinations.
+func (a *ActAssert) WithMatrix(matrix map[string]map[string]bool) *ActAssert {
+	if a.matrix == nil {
+		a.matrix = make(map[string]map[string]bool)
+	}
+	for k, values := range matrix {
+		if a.matrix[k] == nil {
+			a.matrix[k] = make(map[string]bool)
+		}
+		maps.Copy(a.matrix[k], values)
+	}
+	return a
+}
+
+// MatrixJobPlan overrides the matrix combinations of a job matching a combination. The overrides are applied
+// while the workflow is executed and removed afterwards, leaving the plan unchanged. Conditions, outputs and
+// environment variables are overridden by rewriting their expressions, so that the runner evaluates them with
+// the matrix context of each combination. Step results and outputs are overridden through the step results
+// and outputs functions of the job, which tell the combinations apart by their matrix.
+type MatrixJobPlan struct {
+	jobPlan  *JobPlan
+	override *matrixOverride
+}
+
+// matrixOverride contains the overrides of the matrix combinations of a job matching combination.
+type matrixOverride struct {
+	jobID        string
+	workflowFile string
+	combination  map[string]any
+	skip         bool
+	result       Result
+	outputs      map[string]string
+	// steps contains the overrides of the steps, keyed by the index of the step in the job.
+	steps map[int]*matrixStepOverride
+}
+
+type matrixStepOverride struct {
+	skip    bool
+	result  Result
+	outputs map[string]string
+	env     map[string]string
+}
+
+func (o *matrixOverride) clone() *matrixOverride {
+	clone := *o
+	clone.outputs = maps.Clone(o.outputs)
+	clone.steps = make(map[int]*matrixStepOverride, len(o.steps))
+	for i, step := range o.steps {
+		stepClone := *step
+		stepClone.outputs = maps.Clone(step.outputs)
+		stepClone.env = maps.Clone(step.env)
+		clone.steps[i] = &stepClone
+	}
+	return &clone
+}
+
+func cloneMatrixOverrides(overrides []*matrixOverride) []*matrixOverride {
+	clones := make([]*matrixOverride, len(overrides))
+	for i, override := range overrides {
+		clones[i] = override.clone()
+	}
+	return clones
+}
+
+// Matrix returns the plan of the matrix combinations of the job that match combination. Keys of the job
+// matrix not in combination match any value.
+func (j *JobPlan) Matrix(combination map[string]any) *MatrixJobPlan {
+	override := &matrixOverride{
+		jobID:        j.jobRun.JobID,
+		workflowFile: j.jobRun.Workflow.File,
+		combination:  combination,
+		outputs:      map[string]string{},
+		steps:        map[int]*matrixStepOverride{},
+	}
+	j.act.matrixOverrides = append(j.act.matrixOverrides, override)
+	return &MatrixJobPlan{
+		jobPlan:  j,
+		override: override,
+	}
+}
+
+// condition returns an expression that is true for the matrix combinations matching the override.
+func (o *matrixOverride) condition() string {
+	keys := slices.Sorted(maps.Keys(o.combination))
+	var conditions []string
+	for _, k := range keys {
+		conditions = append(conditions, matrixCondition(k, o.combination[k]))
+	}
+	if len(conditions) == 0 {
+		return "true"
+	}
+	return strings.Join(conditions, " && ")
+}
+
+func matrixCondition(k string, v any) string {
+	key := fmt.Sprintf("matrix[%s]", expressionString(k))
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("%s == %s", key, expressionString(v))
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprintf("%s == %v", key, v)
+	default:
+		value, _ := json.Marshal(v)
+		return fmt.Sprintf("toJSON(%s) == toJSON(fromJSON(%s))", key, expressionString(string(value)))
+	}
+}
+
+// expressionString returns s as a string literal of the expression syntax.
+func expressionString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// expressionOf returns value as an expression. A value consisting of a single ${{ }} expression is unwrapped,
+// any other value is treated as a string literal.
+func expressionOf(value string) string {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "${{") && strings.HasSuffix(trimmed, "}}") && strings.Count(trimmed, "${{") == 1 {
+		return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(trimmed, "${{"), "}}"))
+	}
+	return expressionString(value)
+}
+
+// overrideExpression returns an expression evaluating to override for the matching combinations and to
+// original otherwise.
+func (o *matrixOverride) overrideExpression(override, original string) string {
+	return fmt.Sprintf("${{ case(%s, %s, %s) }}", o.condition(), expressionString(override), expressionOf(original))
+}
+
+// SetResult sets the result of the matching combinations. Their steps are not run, but get the result too.
+func (m *MatrixJobPlan) SetResult(result Result) *MatrixJobPlan {
+	m.override.result = result
+	return m
+}
+
+// SetOutput overrides the value of a job output for the matching combinations.
+func (m *MatrixJobPlan) SetOutput(k, v string) *MatrixJobPlan {
+	m.override.outputs[k] = v
+	return m
+}
+
+// Skip skips the matching combinations.
+func (m *MatrixJobPlan) Skip() *MatrixJobPlan {
+	m.override.skip = true
+	return m
+}
+
+// Step returns the plan of the step with the given ID or name for the matching combinations.
+// Panics if the step is not in the job.
+func (m *MatrixJobPlan) Step(name string) *MatrixStepPlan {
+	step, err := m.LookupStep(name)
+	if err != nil {
+		panic(err)
+	}
+	return step
+}
+
+// LookupStep returns the plan of the step with the given ID or name for the matching combinations, or an
+// error if the step is not in the job.
+func (m *MatrixJobPlan) LookupStep(name string) (*MatrixStepPlan, error) {
+	stepPlan, err := m.jobPlan.LookupStep(name)
+	if err != nil {
+		return nil, err
+	}
+	index := slices.Index(m.jobPlan.jobRun.Job().Steps, stepPlan.step)
+	if m.override.steps[index] == nil {
+		m.override.steps[index] = &matrixStepOverride{
+			outputs: map[string]string{},
+			env:     map[string]string{},
+		}
+	}
+	return &MatrixStepPlan{
+		override: m.override.steps[index],
+	}, nil
+}
+
+// RequireStep returns the plan of the step with the given ID or name for the matching combinations.
+// Fails the test if the step is not in the job.
+func (m *MatrixJobPlan) RequireStep(t testing.TB, name string) *MatrixStepPlan {
+	t.Helper()
+	step, err := m.LookupStep(name)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return step
+}
+
+// MatrixStepPlan overrides a step for the matching matrix combinations of a job.
+type MatrixStepPlan struct {
+	override *matrixStepOverride
+}
+
+// SetResult sets the result of the step in the matching combinations. The step is not run.
+func (s *MatrixStepPlan) SetResult(result Result) *MatrixStepPlan {
+	s.override.result = result
+	return s
+}
+
+// SetOutputs sets outputs of the step in the matching combinations.
+func (s *MatrixStepPlan) SetOutputs(o map[string]string) *MatrixStepPlan {
+	maps.Copy(s.override.outputs, o)
+	return s
+}
+
+// SetOutput sets an output of the step in the matching combinations.
+func (s *MatrixStepPlan) SetOutput(k, v string) *MatrixStepPlan {
+	return s.SetOutputs(map[string]string{k: v})
+}
+
+// Skip skips the step in the matching combinations.
+func (s *MatrixStepPlan) Skip() *MatrixStepPlan {
+	s.override.skip = true
+	return s
+}
+
+// SetEnv overrides environment variables of the step in the matching combinations.
+func (s *MatrixStepPlan) SetEnv(envs map[string]string) *MatrixStepPlan {
+	maps.Copy(s.override.env, envs)
+	return s
+}
+
+// applyMatrixOverrides applies the overrides of the matrix combinations to the jobs of plan. The returned
+// function sets the result of the combinations whose result is overridden and restores the plan.
+func (a *ActAssert) applyMatrixOverrides(plan *model.Plan) func() {
+	var restore []func()
+	for _, stage := range plan.Stages {
+		for _, run := range stage.Runs {
+			var overrides []*matrixOverride
+			for _, override := range a.matrixOverrides {
+				if override.jobID == run.JobID && override.workflowFile == run.Workflow.File {
+					overrides = append(overrides, override)
+				}
+			}
+			if len(overrides) > 0 {
+				restore = append(restore, applyJobMatrixOverrides(run, overrides))
+			}
+		}
+	}
+	return func() {
+		for _, runContext := range a.runContexts {
+			for _, override := range a.matrixOverrides {
+				if override.result != "" && override.result != Skipped && override.jobID == runContext.Run.JobID &&
+					matchesCombination(runContext.Matrix, override.combination) {
+					runContext.Run.Job().Result = string(override.result)
+				}
+			}
+		}
+		for _, f := range restore {
+			f()
+		}
+	}
+}
+
+// applyJobMatrixOverrides applies overrides to the job run runs. The returned function restores the job.
+func applyJobMatrixOverrides(run *model.Run, overrides []*matrixOverride) func() {
+	job := run.Job()
+	jobIf, jobOutputs := job.If, job.Outputs
+	stepResultsFunc, stepOutputsFunc := run.StepResultsFunc, run.StepOutputsFunc
+	stepIfs := make([]yaml.Node, len(job.Steps))
+	stepEnvs := make([]yaml.Node, len(job.Steps))
+	stepIDs := make([]string, len(job.Steps))
+	for i, step := range job.Steps {
+		stepIfs[i], stepEnvs[i], stepIDs[i] = step.If, step.Env, stepID(step, i)
+		// Identify the combination running the step to the step results and outputs functions
+		setStepEnv(step, matrixEnvKey, "${{ toJSON(matrix) }}")
+	}
+
+	job.Outputs = maps.Clone(job.Outputs)
+	if job.Outputs == nil {
+		job.Outputs = map[string]string{}
+	}
+	for _, override := range overrides {
+		if override.skip || override.result == Skipped {
+			skipIf(&job.If, override.condition())
+		}
+		for _, k := range slices.Sorted(maps.Keys(override.outputs)) {
+			job.Outputs[k] = override.overrideExpression(override.outputs[k], job.Outputs[k])
+		}
+		for i, stepOverride := range override.steps {
+			step := job.Steps[i]
+			if stepOverride.skip {
+				skipIf(&step.If, override.condition())
+			}
+			for _, k := range slices.Sorted(maps.Keys(stepOverride.env)) {
+				setMappingValue(&step.Env, k, override.overrideExpression(stepOverride.env[k], mappingValue(&step.Env, k)))
+			}
+		}
+	}
+
+	// overridesOf returns the overrides of the combination running step, and the index of the step
+	overridesOf := func(step *model.Step) ([]*matrixOverride, int) {
+		var matrix map[string]any
+		if err := json.Unmarshal([]byte(step.EnvEvaluated[matrixEnvKey]), &matrix); err != nil {
+			return nil, -1
+		}
+		var matching []*matrixOverride
+		for _, override := range overrides {
+			if matchesCombination(matrix, override.combination) {
+				matching = append(matching, override)
+			}
+		}
+		return matching, slices.Index(stepIDs, step.ID)
+	}
+	run.StepResultsFunc = func(step *model.Step) (bool, string) {
+		matching, index := overridesOf(step)
+		var result Result
+		for _, override := range matching {
+			if override.result != "" && override.result != Skipped {
+				result = override.result
+			}
+			if stepOverride, ok := override.steps[index]; ok && stepOverride.result != "" {
+				result = stepOverride.result
+			}
+		}
+		if result != "" {
+			return true, string(result)
+		}
+		if stepResultsFunc != nil {
+			return stepResultsFunc(step)
+		}
+		return false, ""
+	}
+	run.StepOutputsFunc = func(step *model.Step) map[string]string {
+		var outputs map[string]string
+		if stepOutputsFunc != nil {
+			outputs = maps.Clone(stepOutputsFunc(step))
+		}
+		matching, index := overridesOf(step)
+		for _, override := range matching {
+			if stepOverride, ok := override.steps[index]; ok && len(stepOverride.outputs) > 0 {
+				if outputs == nil {
+					outputs = map[string]string{}
+				}
+				maps.Copy(outputs, stepOverride.outputs)
+			}
+		}
+		return outputs
+	}
+
+	return func() {
+		job.If, job.Outputs = jobIf, jobOutputs
+		run.StepResultsFunc, run.StepOutputsFunc = stepResultsFunc, stepOutputsFunc
+		for i, step := range job.Steps {
+			step.If, step.Env = stepIfs[i], stepEnvs[i]
+		}
+	}
+}
+
+// skipIf rewrites the `if` condition in node so that it is false when condition is true.
+func skipIf(node *yaml.Node, condition string) {
+	original := strings.TrimSpace(node.Value)
+	if original == "" {
+		original = "success()"
+	}
+	original = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(original, "${{"), "}}"))
+	node.Kind = yaml.ScalarNode
+	node.Tag = "!!str"
+	node.Value = fmt.Sprintf("!(%s) && (%s)", condition, original)
+}
+
+func mappingValue(node *yaml.Node, k string) string {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == k {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+func setMappingValue(node *yaml.Node, k, v string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == k {
+			node.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+			return
+		}
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v})
+}
+
+// AllSucceeded reports whether every combination succeeded.
+func (m MatrixJobResults) AllSucceeded() bool {
+	return len(m) > 0 && len(m.ByResult(Success)) == len(m)
+}
+
+// AnyFailed reports whether any combination failed.
+func (m MatrixJobResults) AnyFailed() bool {
+	return len(m.ByResult(Failure)) > 0
+}
+
+// ByResult returns the combinations with the given result.
+func (m MatrixJobResults) ByResult(result Result) MatrixJobResults {
+	var filtered MatrixJobResults
+	for _, job := range m {
+		if job.Result() == result {
+			filtered = append(filtered, job)
+		}
+	}
+	return filtered
+}
+
+// Combinations returns the matrix of each combination.
+func (m MatrixJobResults) Combinations() []map[string]any {
+	var combinations []map[string]any
+	for _, job := range m {
+		combinations = append(combinations, job.Matrix())
+	}
+	return combinations
+}
+
+// Result returns the result of the matrix job as a whole, as seen by dependent jobs through
+// `needs.<job_id>.result`: failure if any combination failed, cancelled if any combination was cancelled,
+// skipped if every combination was skipped and success otherwise.
+func (m MatrixJobResults) Result() Result {
+	switch {
+	case m.AnyFailed():
+		return Failure
+	case len(m.ByResult(Cancelled)) > 0:
+		return Cancelled
+	case len(m.ByResult(Skipped)) == len(m):
+		return Skipped
+	default:
+		return Success
+	}
+}
+
+// AssertFailFast asserts that strategy.fail-fast is enabled for the job, that a combination failed and that
+// every combination that had not finished when the first combination failed was cancelled.
+func (m MatrixJobResults) AssertFailFast(t testing.TB) {
+	t.Helper()
+	if len(m) == 0 {
+		t.Fatalf("Matrix job has no combinations")
+	}
+	if strategy := m[0].runContext.Run.Job().Strategy; strategy != nil && !strategy.GetFailFast() {
+		t.Fatalf("Matrix job '%s' does not have fail-fast enabled", m[0].JobName)
+	}
+
+	firstFailure := -1
+	for _, job := range m.ByResult(Failure) {
+		if end, ok := m.finishedAt(job); ok && (firstFailure < 0 || end < firstFailure) {
+			firstFailure = end
+		}
+	}
+	if firstFailure < 0 {
+		t.Fatalf("Matrix job '%s' has no failed combinations", m[0].JobName)
+	}
+
+	var errors []string
+	for _, job := range m {
+		if end, ok := m.finishedAt(job); ok && end <= firstFailure {
+			continue
+		}
+		if result := job.Result(); result != Cancelled {
+			errors = append(errors, fmt.Sprintf("Combination %v was not cancelled, its result is '%s'", job.Matrix(), result))
+		}
+	}
+	if len(errors) > 0 {
+		t.Fatalf("Matrix job '%s' did not fail fast:\n%s", m[0].JobName, strings.Join(errors, "\n"))
+	}
+}
+
+// finishedAt returns the position of the combination among the finished combinations. Combinations predicted
+// with ExecuteDryRun finish in order.
+func (m MatrixJobResults) finishedAt(job *JobResults) (int, bool) {
+	if job.results.events == nil {
+		return slices.Index(m, job), job.Result() != Cancelled
+	}
+	_, end, ok := job.results.events.jobSpan(job.runContext)
+	return end, ok
+}
+
+// AssertMaxParallel asserts that no more combinations ran at the same time than strategy.max-parallel allows.
+func (m MatrixJobResults) AssertMaxParallel(t testing.TB) {
+	t.Helper()
+	if len(m) == 0 {
+		t.Fatalf("Matrix job has no combinations")
+	}
+	strategy := m[0].runContext.Run.Job().Strategy
+	if strategy == nil {
+		t.Fatalf("Matrix job '%s' has no strategy", m[0].JobName)
+	}
+	if parallel := m.maxParallel(); parallel > strategy.GetMaxParallel() {
+		t.Fatalf("Matrix job '%s' ran %d combinations in parallel, max-parallel is %d",
+			m[0].JobName, parallel, strategy.GetMaxParallel())
+	}
+}
+
+// maxParallel returns the maximum number of combinations that were running at the same time.
+func (m MatrixJobResults) maxParallel() int {
+	type boundary struct {
+		position int
+		delta    int
+	}
+	var boundaries []boundary
+	for _, job := range m {
+		if start, end, ok := job.results.events.jobSpan(job.runContext); ok {
+			boundaries = append(boundaries, boundary{start, 1}, boundary{end, -1})
+		}
+	}
+	slices.SortFunc(boundaries, func(a, b boundary) int {
+		return a.position - b.position
+	})
+	running, parallel := 0, 0
+	for _, b := range boundaries {
+		running += b.delta
+		parallel = max(parallel, running)
+	}
+	return parallel
+}
+
+// applyFailFast marks the matrix combinations of fail-fast jobs that the runner stopped because another
+// combination failed as cancelled, as GitHub reports them. The runner reports them as failed, or not at all if
+// they had not started.
+func applyFailFast(runContexts []*runner.RunContext, events *eventRecorder) {
+	type jobKey struct{ workflowFile, jobID string }
+	legs := map[jobKey][]*runner.RunContext{}
+	for _, runContext := range runContexts {
+		strategy := runContext.Run.Job().Strategy
+		if len(runContext.Matrix) == 0 || strategy == nil || !strategy.GetFailFast() {
+			continue
+		}
+		key := jobKey{runContext.Run.Workflow.File, runContext.Run.JobID}
+		legs[key] = append(legs[key], runContext)
+	}
+	for _, runContexts := range legs {
+		firstFailure := -1
+		for _, runContext := range runContexts {
+			if _, end, ok := events.jobSpan(runContext); ok && runContext.Run.Job().Result == string(Failure) &&
+				(firstFailure < 0 || end < firstFailure) {
+				firstFailure = end
+			}
+		}
+		if firstFailure < 0 {
+			continue
+		}
+		for _, runContext := range runContexts {
+			job := runContext.Run.Job()
+			_, end, ok := events.jobSpan(runContext)
+			if (!ok && job.Result == "") || (ok && end > firstFailure && job.Result == string(Failure)) {
+				job.Result = string(Cancelled)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package act_assert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_with_matrix(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/matrix.yaml").
+		WithMatrix(map[string]map[string]bool{"target": {"linux": true}}).
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	build := results.MatrixJob("build")
+	assert.Len(t, build, 2)
+	job := build.Combination(map[string]any{"target": "linux", "node": 20})
+	assert.Equal(t, "building linux with node 20", job.Step("Build").Logs())
+	_, err = build.LookupCombination(map[string]any{"target": "windows"})
+	assert.Error(t, err)
+}
+
+func Test_matrix_combination_overrides(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/matrix.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	windows := workflow.Job("build").Matrix(map[string]any{"target": "windows"})
+	windows.Step("Test").Skip()
+	windows.Step("Build").SetEnv(map[string]string{"TARGET": "win32"})
+	workflow.Job("build").Matrix(map[string]any{"target": "linux", "node": 18}).Skip()
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	build := results.MatrixJob("build")
+	linux20 := build.Combination(map[string]any{"target": "linux", "node": 20})
+	assert.Equal(t, act_assert.Success, linux20.Step("Test").Result())
+	assert.Equal(t, act_assert.Skipped, build.Combination(map[string]any{"target": "linux", "node": 18}).Result())
+	windows20 := build.Combination(map[string]any{"target": "windows", "node": 20})
+	assert.Equal(t, act_assert.Skipped, windows20.Step("Test").Result())
+	assert.Equal(t, "building win32 with node 20", windows20.Step("Build").Logs())
+}
+
+func Test_matrix_aggregate_results(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/matrix_strategy.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	sequential := results.MatrixJob("sequential")
+	assert.True(t, sequential.AllSucceeded())
+	assert.Equal(t, act_assert.Success, sequential.Result())
+	assert.ElementsMatch(t, []map[string]any{{"shard": 1}, {"shard": 2}, {"shard": 3}}, sequential.Combinations())
+	sequential.AssertMaxParallel(t)
+
+	failFast := results.MatrixJob("fail_fast")
+	assert.True(t, failFast.AnyFailed())
+	assert.Equal(t, act_assert.Failure, failFast.Result())
+	assert.Len(t, failFast.ByResult(act_assert.Success), 0)
+	assert.Len(t, failFast.ByResult(act_assert.Cancelled), 2)
+	failFast.AssertFailFast(t)
+}
+
+func Test_matrix_fail_fast_dry_run(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/matrix_strategy.yaml").
+		WithJobName("fail_fast").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("fail_fast").Matrix(map[string]any{"shard": 1}).SetResult(act_assert.Failure)
+	assert.Error(t, workflow.ExecuteDryRun())
+
+	failFast := act_assert.NewResults(*workflow).MatrixJob("fail_fast")
+	assert.Equal(t, act_assert.Failure, failFast.Combination(map[string]any{"shard": 1}).Result())
+	assert.Len(t, failFast.ByResult(act_assert.Cancelled), 2)
+	failFast.AssertFailFast(t)
+}
+
+func Test_matrix_combination_results(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/matrix.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	overridden := workflow.Copy()
+	overridden.Job("build").Matrix(map[string]any{"target": "linux", "node": 18}).SetResult(act_assert.Failure)
+	overridden.Job("build").Matrix(map[string]any{"target": "windows"}).
+		Step("Build").SetOutputs(map[string]string{"artifact": "app-stub"})
+
+	_ = overridden.Execute()
+
+	results := act_assert.NewResults(*overridden)
+	build := results.MatrixJob("build")
+	assert.Equal(t, act_assert.Failure, build.Combination(map[string]any{"target": "linux", "node": 18}).Result())
+	linux20 := build.Combination(map[string]any{"target": "linux", "node": 20})
+	assert.Equal(t, act_assert.Success, linux20.Result())
+	assert.Equal(t, "app-linux", linux20.Step("Build").Outputs()["artifact"])
+	windows18 := build.Combination(map[string]any{"target": "windows", "node": 18})
+	assert.Equal(t, "app-stub", windows18.Step("Build").Outputs()["artifact"])
+	assert.Equal(t, act_assert.Failure, build.Result())
+
+	// The overrides only apply to the executions of the copy they were set on
+	assert.NoError(t, workflow.ExecuteDryRun())
+	assert.True(t, act_assert.NewResults(*workflow).MatrixJob("build").AllSucceeded())
+}
@@ -0,0 +1,260 @@
+package act_assert
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/wd-hopkins/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// ActionCall describes a call to a mocked action.
+type ActionCall struct {
+	// Uses is the action reference of the step, e.g. actions/checkout@v4.
+	Uses string
+	// With contains the evaluated inputs of the step.
+	With map[string]string
+	// Env contains the evaluated environment of the step.
+	Env map[string]string
+	// Matrix contains the matrix combination running the step, or is empty if the job has no matrix.
+	Matrix map[string]any
+}
+
+// ActionResponse describes the effects of a mocked action.
+type ActionResponse struct {
+	// Result is the result of the step. Defaults to Success.
+	Result Result
+	// Outputs are the step outputs, available to later steps as `steps.<id>.outputs`.
+	Outputs map[string]string
+	// Env contains environment variables exported to the later steps of the job, as if written to $GITHUB_ENV.
+	Env map[string]string
+}
+
+// ActionStub implements a mocked action.
+type ActionStub func(call ActionCall) ActionResponse
+
+type actionMock struct {
+	uses string
+	stub ActionStub
+	path string
+}
+
+// MockAction replaces every step using the given action with stub, across all jobs. uses may end in `@*`
+// to match any ref of the action, e.g. aws-actions/configure-aws-credentials@*, and the owner and name
+// support path.Match patterns. The mocked steps are not executed.
+func (a *ActAssert) MockAction(uses string, stub ActionStub) *ActAssert {
+	a.actionMocks = append(a.actionMocks, actionMock{uses: uses, stub: stub})
+	return a
+}
+
+// MockActionWithPath replaces every step using the given action with the local action at dir, across all
+// jobs. dir is relative to the working directory and typically contains a composite action.
+func (a *ActAssert) MockActionWithPath(uses, dir string) *ActAssert {
+	a.actionMocks = append(a.actionMocks, actionMock{uses: uses, path: dir})
+	return a
+}
+
+// matchesUses reports whether the action reference uses matches pattern.
+func matchesUses(pattern, uses string) bool {
+	patternName, patternRef, hasRef := strings.Cut(pattern, "@")
+	name, ref, _ := strings.Cut(uses, "@")
+	if ok, _ := path.Match(patternName, name); !ok {
+		return false
+	}
+	if !hasRef || patternRef == "*" {
+		return true
+	}
+	ok, _ := path.Match(patternRef, ref)
+	return ok
+}
+
+func (a *ActAssert) findActionMock(uses string) *actionMock {
+	if uses == "" {
+		return nil
+	}
+	for i := len(a.actionMocks) - 1; i >= 0; i-- {
+		if matchesUses(a.actionMocks[i].uses, uses) {
+			return &a.actionMocks[i]
+		}
+	}
+	return nil
+}
+
+// applyActionMocks installs the action mocks on the plan and returns a function restoring the plan.
+func (a *ActAssert) applyActionMocks() func() {
+	if len(a.actionMocks) == 0 {
+		return func() {}
+	}
+	var restore []func()
+	for _, stage := range a.plan.Stages {
+		for _, run := range stage.Runs {
+			restore = append(restore, a.mockRunActions(run))
+		}
+	}
+	return func() {
+		for _, f := range restore {
+			f()
+		}
+	}
+}
+
+// stubbedStep identifies a stubbed step run by a matrix combination, given as the JSON of the combination.
+type stubbedStep struct {
+	step   *model.Step
+	matrix string
+}
+
+func (a *ActAssert) mockRunActions(run *model.Run) func() {
+	originalResultsFunc := run.StepResultsFunc
+	originalOutputsFunc := run.StepOutputsFunc
+	originalUses := map[*model.Step]string{}
+	stubs := map[*model.Step]ActionStub{}
+
+	for _, step := range run.Job().Steps {
+		mock := a.findActionMock(step.Uses)
+		if mock == nil {
+			continue
+		}
+		if mock.stub != nil {
+			stubs[step] = mock.stub
+			continue
+		}
+		originalUses[step] = step.Uses
+		step.Uses = localActionPath(mock.path, a.workdir)
+	}
+
+	originalEnvs := map[*model.Step]yaml.Node{}
+	originalEnvOverrides := map[*model.Step]map[string]string{}
+	if len(stubs) > 0 {
+		for _, step := range run.Job().Steps {
+			originalEnvOverrides[step] = maps.Clone(step.EnvOverrides)
+		}
+		// Matrix combinations share the steps, so the responses are keyed by the combination running the step
+		for step := range stubs {
+			originalEnvs[step] = step.Env
+			setStepEnv(step, matrixEnvKey, "${{ toJSON(matrix) }}")
+		}
+		var mu sync.Mutex
+		responses := map[stubbedStep]ActionResponse{}
+		respond := func(step *model.Step) ActionResponse {
+			env := maps.Clone(step.EnvEvaluated)
+			var matrix map[string]any
+			_ = json.Unmarshal([]byte(env[matrixEnvKey]), &matrix)
+			delete(env, matrixEnvKey)
+			response := stubs[step](ActionCall{
+				Uses:   step.Uses,
+				With:   stepInputs(step),
+				Env:    env,
+				Matrix: matrix,
+			})
+			if response.Result == "" {
+				response.Result = Success
+			}
+			exportEnv(run.Job().Steps, step, response.Env)
+			return response
+		}
+
+		run.StepResultsFunc = func(step *model.Step) (bool, string) {
+			if _, ok := stubs[step]; !ok {
+				if originalResultsFunc != nil {
+					return originalResultsFunc(step)
+				}
+				return false, ""
+			}
+			response := respond(step)
+			mu.Lock()
+			responses[stubbedStep{step, step.EnvEvaluated[matrixEnvKey]}] = response
+			mu.Unlock()
+			return true, string(response.Result)
+		}
+		run.StepOutputsFunc = func(step *model.Step) map[string]string {
+			if _, ok := stubs[step]; !ok {
+				if originalOutputsFunc != nil {
+					return originalOutputsFunc(step)
+				}
+				return nil
+			}
+			mu.Lock()
+			response, ok := responses[stubbedStep{step, step.EnvEvaluated[matrixEnvKey]}]
+			mu.Unlock()
+			if !ok {
+				response = respond(step)
+			}
+			return response.Outputs
+		}
+	}
+
+	return func() {
+		run.StepResultsFunc = originalResultsFunc
+		run.StepOutputsFunc = originalOutputsFunc
+		for step, uses := range originalUses {
+			step.Uses = uses
+		}
+		for step, env := range originalEnvs {
+			step.Env = env
+		}
+		for step, envOverrides := range originalEnvOverrides {
+			step.EnvOverrides = envOverrides
+		}
+	}
+}
+
+// localActionPath returns dir as a local action reference relative to workdir.
+func localActionPath(dir, workdir string) string {
+	if filepath.IsAbs(dir) {
+		if absWorkdir, err := filepath.Abs(workdir); err == nil {
+			if rel, err := filepath.Rel(absWorkdir, dir); err == nil {
+				dir = rel
+			}
+		}
+	}
+	dir = filepath.ToSlash(dir)
+	if !strings.HasPrefix(dir, "./") && !strings.HasPrefix(dir, "../") {
+		dir = "./" + dir
+	}
+	return dir
+}
+
+var inputEnvKeyRegex = regexp.MustCompile("[^A-Z0-9-]")
+
+// inputEnvKey returns the name of the environment variable holding the step input k.
+func inputEnvKey(k string) string {
+	return fmt.Sprintf("INPUT_%s", inputEnvKeyRegex.ReplaceAllString(strings.ToUpper(k), "_"))
+}
+
+// stepInputs returns the inputs of the step, evaluated if the step environment has been evaluated.
+func stepInputs(step *model.Step) map[string]string {
+	inputs := make(map[string]string, len(step.With))
+	for k, v := range step.With {
+		if evaluated, ok := step.EnvEvaluated[inputEnvKey(k)]; ok {
+			v = evaluated
+		}
+		inputs[k] = v
+	}
+	return inputs
+}
+
+// exportEnv applies env to the steps following step, as if step had written it to $GITHUB_ENV.
+func exportEnv(steps []*model.Step, step *model.Step, env map[string]string) {
+	if len(env) == 0 {
+		return
+	}
+	after := false
+	for _, s := range steps {
+		if after {
+			if s.EnvOverrides == nil {
+				s.EnvOverrides = map[string]string{}
+			}
+			for k, v := range env {
+				s.EnvOverrides[k] = v
+			}
+		}
+		after = after || s == step
+	}
+}
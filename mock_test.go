@@ -0,0 +1,93 @@
+package act_assert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_mock_action_with_stub(t *testing.T) {
+	var calls []act_assert.ActionCall
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/mocks.yaml").
+		MockAction("actions/checkout@v4", func(call act_assert.ActionCall) act_assert.ActionResponse {
+			return act_assert.ActionResponse{}
+		}).
+		MockAction("aws-actions/configure-aws-credentials@*", func(call act_assert.ActionCall) act_assert.ActionResponse {
+			calls = append(calls, call)
+			return act_assert.ActionResponse{
+				Outputs: map[string]string{"aws-account-id": "123456789012"},
+				Env:     map[string]string{"AWS_REGION": call.With["aws-region"]},
+			}
+		}).
+		Plan()
+	assert.NoError(t, err)
+
+	err = workflow.Execute()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("deploy")
+	assert.Equal(t, act_assert.Success, job.Step("Configure credentials").Result())
+	assert.Equal(t, "account=123456789012 region=eu-west-2", job.Step("Deploy").Logs())
+	if assert.Len(t, calls, 1) {
+		assert.Equal(t, "arn:aws:iam::123456789012:role/deploy", calls[0].With["role-to-assume"])
+	}
+}
+
+func Test_mock_action_with_stub_result(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/mocks.yaml").
+		MockAction("actions/checkout@*", func(call act_assert.ActionCall) act_assert.ActionResponse {
+			return act_assert.ActionResponse{Result: act_assert.Failure}
+		}).
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("deploy")
+	assert.Equal(t, act_assert.Failure, job.Step("Checkout").Result())
+	assert.Equal(t, act_assert.Failure, job.Result())
+}
+
+func Test_mock_action_with_path(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/mocks.yaml").
+		MockActionWithPath("actions/checkout@v4", "test/actions/fake-checkout").
+		MockAction("aws-actions/configure-aws-credentials@*", func(call act_assert.ActionCall) act_assert.ActionResponse {
+			return act_assert.ActionResponse{}
+		}).
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	assert.Contains(t, results.Job("deploy").Step("Checkout").Logs(), "fake checkout with token checkout-token")
+}
+
+func Test_mock_action_with_stub_matrix(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/mocks_matrix.yaml").
+		MockAction("aws-actions/configure-aws-credentials@*", func(call act_assert.ActionCall) act_assert.ActionResponse {
+			return act_assert.ActionResponse{
+				Outputs: map[string]string{"region": call.With["aws-region"]},
+			}
+		}).
+		Plan()
+	assert.NoError(t, err)
+
+	err = workflow.Execute()
+	assert.NoError(t, err)
+
+	// Each combination gets the outputs of its own response
+	results := act_assert.NewResults(*workflow)
+	for _, region := range []string{"eu-west-2", "us-east-1", "ap-south-1"} {
+		job := results.MatrixJob("deploy").Combination(map[string]any{"region": region})
+		assert.Equal(t, region, job.Step("Configure credentials").Outputs()["region"], region)
+		assert.Equal(t, "region="+region, job.Step("Deploy").Logs(), region)
+	}
+}
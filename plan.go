@@ -1,18 +1,25 @@
 package act_assert
 
 import (
+	"encoding/base64"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
 
 	"github.com/wd-hopkins/act/pkg/container"
 	"github.com/wd-hopkins/act/pkg/model"
 )
 
 type JobPlan struct {
+	*JobNode
 	name        string
 	jobRun      *model.Run
 	stepOutputs map[string]map[string]string
+	act         *ActAssert
 }
 
 func (j *JobPlan) SetResult(result Result) *JobPlan {
@@ -84,16 +91,50 @@ func (j *JobPlan) setStepOutputs() *JobPlan {
 	return j
 }
 
+// Step returns the plan of the step with the given ID or name. Panics if the step is not in the job.
 func (j *JobPlan) Step(name string) *StepPlan {
+	step, err := j.LookupStep(name)
+	if err != nil {
+		panic(err)
+	}
+	return step
+}
+
+// LookupStep returns the plan of the step with the given ID or name, or an error if the step is not in the job.
+func (j *JobPlan) LookupStep(name string) (*StepPlan, error) {
+	var step *model.Step
+	var index int
+	for i, s := range j.jobRun.Job().Steps {
+		if stepID(s, i) == name || s.Name == name {
+			step, index = s, i
+			break
+		}
+	}
+	if step == nil {
+		return nil, fmt.Errorf("step '%s' not found in job '%s', available steps: %s",
+			name, j.jobRun.JobID, strings.Join(stepNames(j.jobRun.Job().Steps), ", "))
+	}
 	return &StepPlan{
 		name:    name,
-		step:    j.jobRun.Job().GetStep(name),
+		index:   index,
+		step:    step,
 		jobPlan: j,
+	}, nil
+}
+
+// RequireStep returns the plan of the step with the given ID or name. Fails the test if the step is not in the job.
+func (j *JobPlan) RequireStep(t testing.TB, name string) *StepPlan {
+	t.Helper()
+	step, err := j.LookupStep(name)
+	if err != nil {
+		t.Fatalf("%v", err)
 	}
+	return step
 }
 
 type StepPlan struct {
 	name    string
+	index   int
 	step    *model.Step
 	jobPlan *JobPlan
 }
@@ -114,6 +155,79 @@ func (s *StepPlan) Skip(setResult bool) *StepPlan {
 	return s
 }
 
+const (
+	failExitCodeEnvKey = "ACT_ASSERT_EXIT_CODE"
+	failStderrEnvKey   = "ACT_ASSERT_STDERR"
+	// failScript decodes the standard error, passed base64 encoded so that the runner does not interpolate it.
+	failScript = `[ -z "$ACT_ASSERT_STDERR" ] || printf '%s' "$ACT_ASSERT_STDERR" | base64 -d >&2
+exit "$ACT_ASSERT_EXIT_CODE"`
+)
+
+// Fail replaces the step with one that writes stderr to standard error and exits with exitCode, so that
+// the step fails the way it would for real. Unlike SetResult(Failure), continue-on-error applies and the
+// error output is part of the step logs. The step is only replaced while the workflow is executed.
+// Panics if exitCode is not between 1 and 255, as the step would not fail.
+func (s *StepPlan) Fail(exitCode int, stderr string) *StepPlan {
+	if exitCode < 1 || exitCode > 255 {
+		panic(fmt.Errorf("step '%s' of job '%s': exit code %d does not fail the step, use 1-255",
+			s.name, s.jobPlan.jobRun.JobID, exitCode))
+	}
+	s.jobPlan.act.stepFailures = append(s.jobPlan.act.stepFailures, stepFailure{
+		workflowFile: s.jobPlan.jobRun.Workflow.File,
+		jobID:        s.jobPlan.jobRun.JobID,
+		index:        s.index,
+		exitCode:     exitCode,
+		stderr:       stderr,
+	})
+	return s
+}
+
+type stepFailure struct {
+	workflowFile string
+	jobID        string
+	index        int
+	exitCode     int
+	stderr       string
+}
+
+// applyStepFailures replaces the steps of plan set to fail with StepPlan.Fail. The returned function
+// restores the steps.
+func (a *ActAssert) applyStepFailures(plan *model.Plan) func() {
+	var restore []func()
+	for _, stage := range plan.Stages {
+		for _, run := range stage.Runs {
+			for _, failure := range a.stepFailures {
+				if failure.jobID != run.JobID || failure.workflowFile != run.Workflow.File {
+					continue
+				}
+				step := run.Job().Steps[failure.index]
+				uses, with, script, shell, env := step.Uses, step.With, step.Run, step.Shell, step.EnvOverrides
+				restore = append(restore, func() {
+					step.Uses, step.With, step.Run, step.Shell, step.EnvOverrides = uses, with, script, shell, env
+				})
+				step.Uses = ""
+				step.With = nil
+				step.Run = failScript
+				step.Shell = "sh"
+				step.EnvOverrides = maps.Clone(step.EnvOverrides)
+				if step.EnvOverrides == nil {
+					step.EnvOverrides = map[string]string{}
+				}
+				step.EnvOverrides[failExitCodeEnvKey] = strconv.Itoa(failure.exitCode)
+				step.EnvOverrides[failStderrEnvKey] = ""
+				if failure.stderr != "" {
+					step.EnvOverrides[failStderrEnvKey] = base64.StdEncoding.EncodeToString([]byte(failure.stderr + "\n"))
+				}
+			}
+		}
+	}
+	return func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+	}
+}
+
 func (s *StepPlan) SetOutputs(o map[string]string) *StepPlan {
 	for k, v := range o {
 		if s.jobPlan.stepOutputs[s.name] == nil {
@@ -1,6 +1,8 @@
 package act_assert_test
 
 import (
+	"fmt"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -89,3 +91,133 @@ func Test_skip_step_execution(t *testing.T) {
 	assert.Equal(t, results.Job("main").Step("output").Result(), act_assert.Success)
 	assert.Empty(t, results.Job("main").Step("output").Logs())
 }
+
+func Test_lookup_missing_job(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath(".github/workflows/example.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	_, err = workflow.LookupJob("missing")
+	assert.ErrorContains(t, err, "job 'missing' not found in plan")
+	assert.ErrorContains(t, err, "cleanup")
+
+	_, err = workflow.RequireJob(t, "main").LookupStep("missing")
+	assert.ErrorContains(t, err, "step 'missing' not found in job 'main'")
+	assert.ErrorContains(t, err, "0 (Checkout), 1 (Run a one-line script), output (I am ${{ github.actor }})")
+
+	_, err = workflow.RequireJob(t, "output").LookupStep("0")
+	assert.NoError(t, err)
+}
+
+func Test_require_missing_job(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath(".github/workflows/example.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	tb := requireFatal(func(tb testing.TB) { workflow.RequireJob(tb, "missing") })
+	assert.Contains(t, tb.fatal, "job 'missing' not found in plan")
+
+	tb = requireFatal(func(tb testing.TB) { workflow.RequireJob(tb, "main").RequireStep(tb, "missing") })
+	assert.Contains(t, tb.fatal, "step 'missing' not found in job 'main'")
+
+	tb = requireFatal(func(tb testing.TB) { act_assert.New().RequireAllJobs(tb) })
+	assert.Contains(t, tb.fatal, "Did you forget to call Plan()?")
+
+	tb = requireFatal(func(tb testing.TB) { workflow.RequireJob(tb, "main").RequireStep(tb, "Checkout") })
+	assert.Empty(t, tb.fatal)
+}
+
+// fatalRecorder is a testing.TB recording the message of Fatalf instead of failing the test.
+type fatalRecorder struct {
+	testing.TB
+	fatal string
+}
+
+func (f *fatalRecorder) Helper() {}
+
+func (f *fatalRecorder) Fatalf(format string, args ...any) {
+	f.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// requireFatal runs f with a fatalRecorder, returning it once f returns or fails.
+func requireFatal(f func(tb testing.TB)) *fatalRecorder {
+	tb := &fatalRecorder{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(tb)
+	}()
+	<-done
+	return tb
+}
+
+func Test_lookup_job_without_plan(t *testing.T) {
+	_, err := act_assert.New().LookupJob("main")
+	assert.Error(t, err)
+}
+
+func Test_fail_step(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/failures.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("deploy").
+		Step("deploy").
+		Fail(3, "error: connection to ${{ github.job }} refused")
+
+	err = workflow.Execute()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	deploy := results.Job("deploy").Step("deploy")
+	assert.Equal(t, act_assert.Failure, deploy.Outcome())
+	assert.Equal(t, act_assert.Success, deploy.Conclusion())
+	// The error output is written as is, without evaluating expressions
+	assert.Contains(t, deploy.Logs(), "error: connection to ${{ github.job }} refused")
+	assert.Equal(t, act_assert.Success, results.Job("deploy").Step("handle").Result())
+	assert.Equal(t, act_assert.Success, results.Job("deploy").Result())
+}
+
+func Test_fail_step_dry_run(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/failures.yaml").
+		Plan()
+	assert.NoError(t, err)
+	unchanged := workflow.Copy()
+
+	workflow.Job("deploy").
+		Step("deploy").
+		Fail(3, "error: connection refused")
+
+	err = workflow.ExecuteDryRun()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	deploy := results.Job("deploy").Step("deploy")
+	assert.Equal(t, act_assert.Failure, deploy.Outcome())
+	assert.Equal(t, act_assert.Success, deploy.Conclusion())
+	assert.Equal(t, "error: connection refused", deploy.Logs())
+	assert.Equal(t, act_assert.Success, results.Job("deploy").Step("handle").Result())
+
+	// The step only fails in the executions of the workflow it was set to fail in
+	err = unchanged.ExecuteDryRun()
+	assert.NoError(t, err)
+	deploy = act_assert.NewResults(*unchanged).Job("deploy").Step("deploy")
+	assert.Equal(t, act_assert.Success, deploy.Outcome())
+	assert.Empty(t, deploy.Logs())
+}
+
+func Test_fail_step_exit_code_zero(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/failures.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	assert.Panics(t, func() {
+		workflow.Job("deploy").Step("deploy").Fail(0, "")
+	})
+}
@@ -0,0 +1,276 @@
+package act_assert
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// JSONReport is the document written by Results.WriteJSON.
+type JSONReport struct {
+	Result Result          `json:"result"`
+	Jobs   []JSONJobReport `json:"jobs"`
+}
+
+// JSONJobReport is the report of a job, or of a matrix combination of a job.
+type JSONJobReport struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Matrix          map[string]any    `json:"matrix,omitempty"`
+	Result          Result            `json:"result"`
+	Start           *time.Time        `json:"start,omitempty"`
+	End             *time.Time        `json:"end,omitempty"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	Outputs         map[string]string `json:"outputs,omitempty"`
+	Failure         string            `json:"failure,omitempty"`
+	Logs            string            `json:"logs,omitempty"`
+	Steps           []JSONStepReport  `json:"steps"`
+}
+
+// JSONStepReport is the report of a step.
+type JSONStepReport struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Result          Result            `json:"result"`
+	Outcome         Result            `json:"outcome,omitempty"`
+	Conclusion      Result            `json:"conclusion,omitempty"`
+	Start           *time.Time        `json:"start,omitempty"`
+	End             *time.Time        `json:"end,omitempty"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	Outputs         map[string]string `json:"outputs,omitempty"`
+	Failure         string            `json:"failure,omitempty"`
+	Logs            string            `json:"logs,omitempty"`
+}
+
+// WriteJSON writes the results of every job and step, with their durations, logs and failure reasons, as an
+// indented JSONReport document.
+func (r *Results) WriteJSON(w io.Writer) error {
+	report := JSONReport{Result: Success, Jobs: []JSONJobReport{}}
+	for _, job := range r.Jobs() {
+		jobReport := JSONJobReport{
+			ID:      job.ID(),
+			Name:    job.JobName,
+			Matrix:  job.Matrix(),
+			Result:  reportResult(job.Result()),
+			Outputs: job.Outputs(),
+			Failure: job.failureReason(),
+			Logs:    job.Logs(),
+			Steps:   []JSONStepReport{},
+		}
+		if start, end, ok := job.timing(); ok {
+			jobReport.Start, jobReport.End, jobReport.DurationSeconds = &start, &end, end.Sub(start).Seconds()
+		}
+		for _, step := range job.Steps() {
+			stepReport := JSONStepReport{
+				ID:         step.ID(),
+				Name:       step.StepName,
+				Result:     stepReportResult(job, step),
+				Outcome:    step.Outcome(),
+				Conclusion: step.Conclusion(),
+				Outputs:    step.Outputs(),
+				Failure:    step.failureReason(),
+				Logs:       step.Logs(),
+			}
+			if start, end, ok := step.timing(); ok {
+				stepReport.Start, stepReport.End, stepReport.DurationSeconds = &start, &end, end.Sub(start).Seconds()
+			}
+			jobReport.Steps = append(jobReport.Steps, stepReport)
+		}
+		switch {
+		case jobReport.Result == Failure || jobReport.Result == unknownResult:
+			report.Result = Failure
+		case jobReport.Result == Cancelled && report.Result != Failure:
+			report.Result = Cancelled
+		}
+		report.Jobs = append(report.Jobs, jobReport)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as a JUnit XML report, with a test suite per job, or matrix combination of
+// a job, and a test case per step. Jobs without steps are reported as a single test case. Cancelled jobs and
+// steps are reported as errors, and those that did not finish as failures.
+func (r *Results) WriteJUnit(w io.Writer) error {
+	report := junitTestSuites{}
+	var total time.Duration
+	for _, job := range r.Jobs() {
+		name := job.displayName()
+		suite := junitTestSuite{Name: name}
+		start, end, ok := job.timing()
+		if ok {
+			suite.Timestamp = start.Format(time.RFC3339)
+			total += end.Sub(start)
+		}
+		suite.Time = junitTime(end.Sub(start))
+
+		steps := job.Steps()
+		if len(steps) == 0 {
+			suite.TestCases = append(suite.TestCases, junitCase(name, name, reportResult(job.Result()), end.Sub(start),
+				job.failureReason(), job.Logs()))
+		}
+		for _, step := range steps {
+			stepStart, stepEnd, _ := step.timing()
+			suite.TestCases = append(suite.TestCases, junitCase(step.StepName, name, stepReportResult(job, step),
+				stepEnd.Sub(stepStart), step.failureReason(), step.Logs()))
+		}
+		for _, testCase := range suite.TestCases {
+			suite.Tests++
+			if testCase.Failure != nil {
+				suite.Failures++
+			}
+			if testCase.Error != nil {
+				suite.Errors++
+			}
+			if testCase.Skipped != nil {
+				suite.Skipped++
+			}
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+		report.Suites = append(report.Suites, suite)
+	}
+	report.Time = junitTime(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitCase(name, className string, result Result, duration time.Duration, failure, logs string) junitTestCase {
+	testCase := junitTestCase{Name: name, ClassName: className, Time: junitTime(duration), SystemOut: logs}
+	switch result {
+	case Failure:
+		testCase.Failure = &junitFailure{Message: failure, Text: logs}
+	case unknownResult:
+		testCase.Failure = &junitFailure{Message: "did not finish", Text: logs}
+	case Skipped:
+		testCase.Skipped = &junitSkipped{}
+	case Cancelled:
+		testCase.Error = &junitFailure{Message: string(Cancelled), Text: logs}
+	}
+	return testCase
+}
+
+func junitTime(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
+
+// unknownResult is reported for jobs and steps that did not finish, e.g. because of an infrastructure error.
+const unknownResult Result = "unknown"
+
+// reportResult returns the result of a job, or unknownResult if the job did not finish.
+func reportResult(result Result) Result {
+	return cmp.Or(result, unknownResult)
+}
+
+// stepReportResult returns the result of a step of job. Steps of a job skipped or cancelled before they ran
+// have the result of the job.
+func stepReportResult(job *JobResults, step *StepResults) Result {
+	if result := step.Result(); result != "" {
+		return result
+	}
+	if result := job.Result(); result == Skipped || result == Cancelled {
+		return result
+	}
+	return unknownResult
+}
+
+// displayName returns the name of the job, followed by its matrix combination if it has one.
+func (j *JobResults) displayName() string {
+	matrix := j.Matrix()
+	if len(matrix) == 0 {
+		return j.JobName
+	}
+	var values []string
+	for _, k := range slices.Sorted(maps.Keys(matrix)) {
+		values = append(values, fmt.Sprint(matrix[k]))
+	}
+	return fmt.Sprintf("%s (%s)", j.JobName, strings.Join(values, ", "))
+}
+
+// failureReason describes why the job failed, listing its failed steps. Empty if the job did not fail.
+func (j *JobResults) failureReason() string {
+	if j.Result() != Failure {
+		return ""
+	}
+	var reasons []string
+	for _, step := range j.Steps() {
+		if reason := step.failureReason(); reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	if len(reasons) == 0 {
+		return fmt.Sprintf("job '%s' failed", j.JobName)
+	}
+	return strings.Join(reasons, "\n")
+}
+
+// failureReason describes why the step failed, using its error annotations. Empty if the step did not fail.
+func (s *StepResults) failureReason() string {
+	if s.Result() != Failure {
+		return ""
+	}
+	reason := fmt.Sprintf("step '%s' failed", s.StepName)
+	if errors := s.Annotations().Errors().Messages(); len(errors) > 0 {
+		reason += ": " + strings.Join(errors, "; ")
+	}
+	return reason
+}
@@ -0,0 +1,104 @@
+package act_assert_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func dryRunFailingBuild(t *testing.T) *act_assert.Results {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/dry_run.yaml").
+		WithEventPayload(act_assert.PushEvent{Ref: "refs/heads/main"}).
+		Plan()
+	assert.NoError(t, err)
+	workflow.Job("build").Step("version").SetResult(act_assert.Failure)
+	_ = workflow.ExecuteDryRun()
+	return act_assert.NewResults(*workflow)
+}
+
+func Test_write_json_report(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, dryRunFailingBuild(t).WriteJSON(&buf))
+
+	var report act_assert.JSONReport
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(t, act_assert.Failure, report.Result)
+
+	jobs := map[string]act_assert.JSONJobReport{}
+	for _, job := range report.Jobs {
+		jobs[job.Name] = job
+	}
+	assert.Equal(t, act_assert.Failure, jobs["build"].Result)
+	assert.Equal(t, "step 'Version' failed", jobs["build"].Failure)
+	assert.Equal(t, act_assert.Failure, jobs["build"].Steps[0].Result)
+	assert.Equal(t, act_assert.Skipped, jobs["deploy"].Result)
+	assert.Equal(t, act_assert.Success, jobs["cleanup"].Result)
+}
+
+func Test_write_junit_report(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, dryRunFailingBuild(t).WriteJUnit(&buf))
+
+	var report struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name      string `xml:"name,attr"`
+			TestCases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, "build", report.Suites[0].Name)
+	assert.Equal(t, "Version", report.Suites[0].TestCases[0].Name)
+	assert.Equal(t, "step 'Version' failed", report.Suites[0].TestCases[0].Failure.Message)
+}
+
+func Test_report_cancelled(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/dry_run.yaml").
+		WithEventPayload(act_assert.PushEvent{Ref: "refs/heads/main"}).
+		Plan()
+	assert.NoError(t, err)
+	workflow.Job("build").Step("version").SetResult(act_assert.Cancelled)
+	_ = workflow.ExecuteDryRun()
+	results := act_assert.NewResults(*workflow)
+
+	var buf bytes.Buffer
+	assert.NoError(t, results.WriteJSON(&buf))
+	var report act_assert.JSONReport
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(t, act_assert.Cancelled, report.Result)
+	assert.Equal(t, act_assert.Cancelled, report.Jobs[0].Result)
+
+	buf.Reset()
+	assert.NoError(t, results.WriteJUnit(&buf))
+	var junit struct {
+		Failures int `xml:"failures,attr"`
+		Errors   int `xml:"errors,attr"`
+		Suites   []struct {
+			TestCases []struct {
+				Name  string `xml:"name,attr"`
+				Error *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"error"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &junit))
+	assert.Equal(t, 0, junit.Failures)
+	assert.Equal(t, "Version", junit.Suites[0].TestCases[0].Name)
+	if assert.NotNil(t, junit.Suites[0].TestCases[0].Error) {
+		assert.Equal(t, "cancelled", junit.Suites[0].TestCases[0].Error.Message)
+	}
+}
@@ -1,9 +1,11 @@
 package act_assert
 
 import (
+	"cmp"
 	"context"
 	"fmt"
-	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -14,40 +16,95 @@ import (
 type Result string
 
 const (
-	Success Result = "success"
-	Failure Result = "failure"
-	Skipped Result = "skipped"
+	Success   Result = "success"
+	Failure   Result = "failure"
+	Skipped   Result = "skipped"
+	Cancelled Result = "cancelled"
 )
 
 type Results struct {
-	runContexts []*runner.RunContext
+	runContexts        []*runner.RunContext
+	events             *eventRecorder
+	artifactServerPath string
+	runID              string
+	cache              *cacheServer
+	// recordedCommandCalls are the calls of the commands stubbed with JobPlan.StubCommand.
+	recordedCommandCalls []commandCall
+	stepSummaries        *stepSummaries
 }
 
 func NewResults(act ActAssert) *Results {
 	return &Results{
-		runContexts: act.runContexts,
+		runContexts:          act.runContexts,
+		events:               act.events,
+		artifactServerPath:   act.artifactServerPath,
+		runID:                act.runID(),
+		cache:                act.cache,
+		recordedCommandCalls: act.commandCalls,
+		stepSummaries:        act.stepSummaries,
 	}
 }
 
+// Jobs returns the results of every job that ran, with a JobResults per matrix combination. Jobs calling a
+// reusable workflow are represented by the jobs of the called workflow.
+func (r *Results) Jobs() []*JobResults {
+	var jobs []*JobResults
+	var collect func(ctx *runner.RunContext)
+	collect = func(ctx *runner.RunContext) {
+		if ctx.ChildContexts != nil {
+			for _, childContext := range *ctx.ChildContexts {
+				collect(childContext)
+			}
+			return
+		}
+		jobs = append(jobs, &JobResults{JobName: cmp.Or(ctx.JobName, ctx.Run.JobID), runContext: ctx, results: r})
+	}
+	for _, ctx := range r.runContexts {
+		collect(ctx)
+	}
+	return jobs
+}
+
+// Job returns the results of the job with the given ID or name. Panics if the job is not in the results.
 func (r *Results) Job(name string) *JobResults {
+	job, err := r.LookupJob(name)
+	if err != nil {
+		panic(err)
+	}
+	return job
+}
+
+// LookupJob returns the results of the job with the given ID or name, or an error if the job is not in the results.
+func (r *Results) LookupJob(name string) (*JobResults, error) {
 	for _, ctx := range r.runContexts {
-		if job := getJobWithName(ctx, name); job != nil {
-			return job
+		if job := r.getJobWithName(ctx, name); job != nil {
+			return job, nil
 		}
 	}
-	panic(fmt.Sprintf("Job %s not found in results", name))
+	return nil, fmt.Errorf("job '%s' not found in results, available jobs: %s", name, strings.Join(r.jobNames(), ", "))
 }
 
-func getJobWithName(ctx *runner.RunContext, name string) *JobResults {
+// RequireJob returns the results of the job with the given ID or name. Fails the test if the job is not in the results.
+func (r *Results) RequireJob(t testing.TB, name string) *JobResults {
+	t.Helper()
+	job, err := r.LookupJob(name)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return job
+}
+
+func (r *Results) getJobWithName(ctx *runner.RunContext, name string) *JobResults {
 	if ctx.JobName == name || ctx.Run.JobID == name {
 		return &JobResults{
 			JobName:    ctx.JobName,
 			runContext: ctx,
+			results:    r,
 		}
 	}
 	if ctx.ChildContexts != nil {
 		for _, childContext := range *ctx.ChildContexts {
-			if childCtx := getJobWithName(childContext, name); childCtx != nil {
+			if childCtx := r.getJobWithName(childContext, name); childCtx != nil {
 				return childCtx
 			}
 		}
@@ -55,13 +112,49 @@ func getJobWithName(ctx *runner.RunContext, name string) *JobResults {
 	return nil
 }
 
+// jobNames returns the IDs and names of all jobs in the results, including jobs of called reusable workflows.
+func (r *Results) jobNames() []string {
+	var names []string
+	var collect func(ctx *runner.RunContext)
+	collect = func(ctx *runner.RunContext) {
+		if !slices.Contains(names, ctx.Run.JobID) {
+			names = append(names, ctx.Run.JobID)
+		}
+		if ctx.JobName != "" && !slices.Contains(names, ctx.JobName) {
+			names = append(names, ctx.JobName)
+		}
+		if ctx.ChildContexts != nil {
+			for _, childContext := range *ctx.ChildContexts {
+				collect(childContext)
+			}
+		}
+	}
+	for _, ctx := range r.runContexts {
+		collect(ctx)
+	}
+	return names
+}
+
+// MatrixJob returns the results of every matrix combination of the job with the given ID or name.
+// Panics if the job is not in the results.
 func (r *Results) MatrixJob(name string) MatrixJobResults {
+	matrixResults, err := r.LookupMatrixJob(name)
+	if err != nil {
+		panic(err)
+	}
+	return matrixResults
+}
+
+// LookupMatrixJob returns the results of every matrix combination of the job with the given ID or name,
+// or an error if the job is not in the results.
+func (r *Results) LookupMatrixJob(name string) (MatrixJobResults, error) {
 	var matrixResults MatrixJobResults
 	for _, ctx := range r.runContexts {
 		if ctx.Run.JobID == name {
 			matrixResults = append(matrixResults, &JobResults{
 				JobName:    ctx.Run.JobID,
 				runContext: ctx,
+				results:    r,
 			})
 		}
 		if ctx.ChildContexts != nil {
@@ -70,13 +163,25 @@ func (r *Results) MatrixJob(name string) MatrixJobResults {
 					matrixResults = append(matrixResults, &JobResults{
 						JobName:    childContext.Run.JobID,
 						runContext: childContext,
+						results:    r,
 					})
 				}
 			}
 		}
 	}
 	if len(matrixResults) <= 0 {
-		panic(fmt.Sprintf("Job %s not found in results", name))
+		return nil, fmt.Errorf("job '%s' not found in results, available jobs: %s", name, strings.Join(r.jobNames(), ", "))
+	}
+	return matrixResults, nil
+}
+
+// RequireMatrixJob returns the results of every matrix combination of the job with the given ID or name.
+// Fails the test if the job is not in the results.
+func (r *Results) RequireMatrixJob(t testing.TB, name string) MatrixJobResults {
+	t.Helper()
+	matrixResults, err := r.LookupMatrixJob(name)
+	if err != nil {
+		t.Fatalf("%v", err)
 	}
 	return matrixResults
 }
@@ -84,6 +189,7 @@ func (r *Results) MatrixJob(name string) MatrixJobResults {
 type JobResults struct {
 	JobName    string
 	runContext *runner.RunContext
+	results    *Results
 }
 
 func (j *JobResults) Succeeded() bool {
@@ -98,6 +204,11 @@ func (j *JobResults) Failed() bool {
 	return j.runContext.Run.Job().Result == string(Failure)
 }
 
+// Cancelled reports whether the job was cancelled, e.g. with JobPlan.SetResult(Cancelled).
+func (j *JobResults) Cancelled() bool {
+	return j.runContext.Run.Job().Result == string(Cancelled)
+}
+
 func (j *JobResults) Result() Result {
 	if j.runContext.Run.Job().Result != "" {
 		return Result(j.runContext.Run.Job().Result)
@@ -114,23 +225,63 @@ func (j *JobResults) Result() Result {
 	return ""
 }
 
+// Matrix returns the matrix combination the job ran with, if it is a matrix job.
+func (j *JobResults) Matrix() map[string]any {
+	return j.runContext.Matrix
+}
+
+// ID returns the ID of the job.
+func (j *JobResults) ID() string {
+	return j.runContext.Run.JobID
+}
+
 func (j *JobResults) Outputs() map[string]string {
 	return j.runContext.Run.Job().Outputs
 }
 
+// Masks returns the values masked in the job logs, both those added with `::add-mask::` and the secret values
+// set with WithSecrets, WithSecretsFile and WithSecretsFromEnv.
 func (j *JobResults) Masks() []string {
-	return j.runContext.Masks
+	return append(slices.Clone(j.runContext.Masks), secretMasks(j.runContext)...)
 }
 
 func (j *JobResults) Logs() string {
 	if j.runContext.ChildContexts == nil {
-		return aggregateStepLogs(j.runContext)
+		return maskSecrets(aggregateStepLogs(j.runContext), j.runContext)
 	}
-	return aggregateReusableJobLogs(j.runContext)
+	return maskSecrets(aggregateReusableJobLogs(j.runContext), j.runContext)
 }
 
+// CommandCalls returns the calls all steps of the job made to the command name, stubbed with JobPlan.StubCommand.
+func (j *JobResults) CommandCalls(name string) []CommandCall {
+	return j.results.commandCalls(j.runContext, name, -1)
+}
+
+// Annotations returns the error, warning, notice, debug and group workflow commands emitted by the steps of
+// the job, including the jobs of a called reusable workflow. Debug commands are only recorded when the
+// logrus log level is Debug.
+func (j *JobResults) Annotations() Annotations {
+	if j.runContext.ChildContexts != nil {
+		var annotations Annotations
+		for _, childContext := range *j.runContext.ChildContexts {
+			annotations = append(annotations, eventAnnotations(j.results.events.jobEvents(childContext))...)
+		}
+		return annotations
+	}
+	return eventAnnotations(j.results.events.jobEvents(j.runContext))
+}
+
+// Summary returns the job summary, i.e. the concatenated summaries of its steps.
 func (j *JobResults) Summary() string {
-	return j.runContext.Summary
+	if j.runContext.Summary != "" {
+		return j.runContext.Summary
+	}
+	var summary strings.Builder
+	for i := range j.runContext.Run.Job().Steps {
+		stepSummary, _ := j.results.stepSummaries.step(j.runContext, i)
+		summary.WriteString(stepSummary)
+	}
+	return summary.String()
 }
 
 func (j *JobResults) GetInputs() map[string]string {
@@ -163,37 +314,293 @@ func (j *JobResults) WasCalledWith(inputs map[string]string) (bool, error) {
 	return true, nil
 }
 
+// Steps returns the results of every step of the job, in the order they are defined.
+func (j *JobResults) Steps() []*StepResults {
+	var steps []*StepResults
+	for _, step := range j.runContext.Run.Job().Steps {
+		steps = append(steps, &StepResults{
+			StepName:   cmp.Or(step.Name, step.ID),
+			step:       step,
+			runContext: j.runContext,
+			results:    j.results,
+		})
+	}
+	return steps
+}
+
+// Step returns the results of the step with the given ID or name. Panics if the step is not in the job.
 func (j *JobResults) Step(name string) *StepResults {
+	step, err := j.LookupStep(name)
+	if err != nil {
+		panic(err)
+	}
+	return step
+}
+
+// LookupStep returns the results of the step with the given ID or name, or an error if the step is not in the job.
+func (j *JobResults) LookupStep(name string) (*StepResults, error) {
 	jobType, _ := j.runContext.Run.Job().Type()
 	if jobType == model.JobTypeReusableWorkflowLocal ||
 		jobType == model.JobTypeReusableWorkflowRemote ||
 		j.runContext.ChildContexts != nil {
-		panic("Job is calling a reusable workflow and has no steps")
+		return nil, fmt.Errorf("job '%s' is calling a reusable workflow and has no steps", j.JobName)
 	}
 	for _, step := range j.runContext.Run.Job().Steps {
 		if step.ID == name || step.Name == name {
 			return &StepResults{
-				StepName: name,
-				step:     step,
-			}
+				StepName:   name,
+				step:       step,
+				runContext: j.runContext,
+				results:    j.results,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("step '%s' not found in job '%s' results, available steps: %s",
+		name, j.JobName, strings.Join(stepNames(j.runContext.Run.Job().Steps), ", "))
+}
+
+// RequireStep returns the results of the step with the given ID or name. Fails the test if the step is not in the job.
+func (j *JobResults) RequireStep(t testing.TB, name string) *StepResults {
+	t.Helper()
+	step, err := j.LookupStep(name)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return step
+}
+
+// stepNames returns the ID of each step, along with its name if it has one. Steps without an ID are identified
+// by their index, as the runner does.
+func stepNames(steps []*model.Step) []string {
+	var names []string
+	for i, step := range steps {
+		id := stepID(step, i)
+		if step.Name != "" && step.Name != id {
+			names = append(names, fmt.Sprintf("%s (%s)", id, step.Name))
+		} else {
+			names = append(names, id)
 		}
 	}
-	panic("Step not found in Job results")
+	return names
+}
+
+// stepID returns the ID of the step at index i, which is its index if the step has no ID, as assigned by the
+// runner when the job runs.
+func stepID(step *model.Step, i int) string {
+	if step.ID == "" {
+		return strconv.Itoa(i)
+	}
+	return step.ID
 }
 
 type MatrixJobResults []*JobResults
 
+// Combination returns the results of the matrix combination matching combination. Keys of the job matrix not
+// in combination match any value. Panics if no combination, or more than one combination, matches.
+func (m MatrixJobResults) Combination(combination map[string]any) *JobResults {
+	job, err := m.LookupCombination(combination)
+	if err != nil {
+		panic(err)
+	}
+	return job
+}
+
+// LookupCombination returns the results of the matrix combination matching combination, or an error if no
+// combination, or more than one combination, matches.
+func (m MatrixJobResults) LookupCombination(combination map[string]any) (*JobResults, error) {
+	var matches []*JobResults
+	var combinations []string
+	for _, job := range m {
+		if matchesCombination(job.runContext.Matrix, combination) {
+			matches = append(matches, job)
+		}
+		combinations = append(combinations, fmt.Sprint(job.runContext.Matrix))
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return nil, fmt.Errorf("matrix combination %v not found in results, available combinations: %s",
+			combination, strings.Join(combinations, ", "))
+	default:
+		return nil, fmt.Errorf("matrix combination %v matches %d combinations", combination, len(matches))
+	}
+}
+
+// RequireCombination returns the results of the matrix combination matching combination. Fails the test if
+// no combination, or more than one combination, matches.
+func (m MatrixJobResults) RequireCombination(t testing.TB, combination map[string]any) *JobResults {
+	t.Helper()
+	job, err := m.LookupCombination(combination)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return job
+}
+
+func matchesCombination(matrix map[string]interface{}, combination map[string]any) bool {
+	for k, v := range combination {
+		actual, ok := matrix[k]
+		if !ok || fmt.Sprint(actual) != fmt.Sprint(v) {
+			return false
+		}
+	}
+	return true
+}
+
 type StepResults struct {
-	StepName string
-	step     *model.Step
+	StepName   string
+	step       *model.Step
+	runContext *runner.RunContext
+	results    *Results
+}
+
+// ID returns the ID of the step.
+func (s *StepResults) ID() string {
+	return s.step.ID
 }
 
 func (s *StepResults) Result() Result {
 	return Result(s.step.Result)
 }
 
+// Outcome returns the result of the step before continue-on-error is applied.
+func (s *StepResults) Outcome() Result {
+	if s.step.Result == string(Cancelled) {
+		return Cancelled
+	}
+	if stepResult, ok := s.runContext.StepResults[s.step.ID]; ok && stepResult != nil {
+		return Result(stepResult.Outcome.String())
+	}
+	return ""
+}
+
+// Conclusion returns the result of the step after continue-on-error is applied, i.e. a step that failed with
+// continue-on-error set has a failure outcome and a success conclusion.
+func (s *StepResults) Conclusion() Result {
+	if s.step.Result == string(Cancelled) {
+		return Cancelled
+	}
+	if stepResult, ok := s.runContext.StepResults[s.step.ID]; ok && stepResult != nil {
+		return Result(stepResult.Conclusion.String())
+	}
+	return ""
+}
+
+// Outputs returns the outputs the step wrote to $GITHUB_OUTPUT, or that were set with StepPlan.SetOutputs.
+func (s *StepResults) Outputs() map[string]string {
+	if stepResult, ok := s.runContext.StepResults[s.step.ID]; ok && stepResult != nil {
+		return stepResult.Outputs
+	}
+	return nil
+}
+
+// EnvExports returns the environment variables the step wrote to $GITHUB_ENV. They are determined by comparing
+// the environment of the step with the environment of the next step that ran, or the final job environment.
+func (s *StepResults) EnvExports() map[string]string {
+	after, declared := s.envAfter()
+	exports := map[string]string{}
+	for k, v := range after {
+		if isStepScopedEnv(k) || declared[k] {
+			continue
+		}
+		if before, ok := s.step.EnvEvaluated[k]; !ok || before != v {
+			exports[k] = v
+		}
+	}
+	return exports
+}
+
+// PathAdditions returns the directories the step wrote to $GITHUB_PATH, in the order they were added to PATH.
+func (s *StepResults) PathAdditions() []string {
+	var after []string
+	if next := s.nextStep(); next != nil {
+		after = strings.Split(next.EnvEvaluated["PATH"], ":")
+	} else {
+		after = s.runContext.ExtraPath
+	}
+	before := strings.Split(s.step.EnvEvaluated["PATH"], ":")
+	var additions []string
+	for _, dir := range after {
+		if dir != "" && !slices.Contains(before, dir) && !slices.Contains(additions, dir) {
+			additions = append(additions, dir)
+		}
+	}
+	return additions
+}
+
+// nextStep returns the step following this step in the job that ran, if any.
+func (s *StepResults) nextStep() *model.Step {
+	steps := s.runContext.Run.Job().Steps
+	i := slices.Index(steps, s.step)
+	if i < 0 {
+		return nil
+	}
+	for _, step := range steps[i+1:] {
+		if step.EnvEvaluated != nil {
+			return step
+		}
+	}
+	return nil
+}
+
+// envAfter returns the environment following the step, along with the variables it contains that were
+// declared rather than exported.
+func (s *StepResults) envAfter() (map[string]string, map[string]bool) {
+	declared := map[string]bool{}
+	if next := s.nextStep(); next != nil {
+		for k := range next.Environment() {
+			declared[k] = true
+		}
+		for k := range next.EnvOverrides {
+			declared[k] = true
+		}
+		return next.EnvEvaluated, declared
+	}
+	for k := range s.step.Environment() {
+		declared[k] = true
+	}
+	return s.runContext.Env, declared
+}
+
+// isStepScopedEnv reports whether k is an environment variable the runner sets for each step.
+func isStepScopedEnv(k string) bool {
+	if strings.HasPrefix(k, "INPUT_") || strings.HasPrefix(k, "STATE_") {
+		return true
+	}
+	switch k {
+	case "PATH", "GITHUB_ACTION", "GITHUB_ACTION_PATH", "GITHUB_ACTION_REF", "GITHUB_ACTION_REPOSITORY",
+		"GITHUB_ENV", "GITHUB_OUTPUT", "GITHUB_PATH", "GITHUB_STATE", "GITHUB_STEP_SUMMARY", stepEnvKey, matrixEnvKey:
+		return true
+	}
+	return false
+}
+
+// index returns the index of the step in the job.
+func (s *StepResults) index() int {
+	return slices.Index(s.runContext.Run.Job().Steps, s.step)
+}
+
 func (s *StepResults) Logs() string {
-	return strings.TrimSpace(s.step.Logs)
+	return strings.TrimSpace(maskSecrets(s.step.Logs, s.runContext))
+}
+
+// Summary returns the summary the step wrote to $GITHUB_STEP_SUMMARY. Requires ActAssert.CaptureStepSummaries.
+func (s *StepResults) Summary() string {
+	summary, _ := s.results.stepSummaries.step(s.runContext, s.index())
+	return summary
+}
+
+// Annotations returns the error, warning, notice, debug and group workflow commands emitted by the step.
+func (s *StepResults) Annotations() Annotations {
+	return eventAnnotations(s.results.events.jobEvents(s.runContext)).filter(func(annotation Annotation) bool {
+		return annotation.StepID == s.step.ID
+	})
+}
+
+// CommandCalls returns the calls the step made to the command name, stubbed with JobPlan.StubCommand.
+func (s *StepResults) CommandCalls(name string) []CommandCall {
+	return s.results.commandCalls(s.runContext, name, s.index())
 }
 
 func (s *StepResults) AssertCalledWith(t *testing.T, inputs map[string]string) {
@@ -205,9 +612,7 @@ func (s *StepResults) AssertCalledWith(t *testing.T, inputs map[string]string) {
 	envs := s.step.EnvEvaluated
 	var errors []string
 	for k, expected := range inputs {
-		envKey := regexp.MustCompile("[^A-Z0-9-]").ReplaceAllString(strings.ToUpper(k), "_")
-		envKey = fmt.Sprintf("INPUT_%s", strings.ToUpper(envKey))
-		if actual, ok := envs[envKey]; ok {
+		if actual, ok := envs[inputEnvKey(k)]; ok {
 			if actual != expected {
 				errors = append(errors, fmt.Sprintf("Input '%s' expected '%s' != actual '%s'", k, expected, actual))
 			}
@@ -244,6 +649,31 @@ func aggregateStepLogs(runContext *runner.RunContext) string {
 	return logs
 }
 
+// secretMasks returns the non-empty secret values available to the job, sorted longest first so that
+// a secret containing another secret is masked as a whole.
+func secretMasks(runContext *runner.RunContext) []string {
+	if runContext.Config == nil {
+		return nil
+	}
+	var masks []string
+	for _, v := range runContext.Config.Secrets {
+		if v != "" && !slices.Contains(masks, v) {
+			masks = append(masks, v)
+		}
+	}
+	slices.SortFunc(masks, func(a, b string) int {
+		return len(b) - len(a)
+	})
+	return masks
+}
+
+func maskSecrets(logs string, runContext *runner.RunContext) string {
+	for _, mask := range secretMasks(runContext) {
+		logs = strings.ReplaceAll(logs, mask, "***")
+	}
+	return logs
+}
+
 func prependName(logs, name string, runContext *runner.RunContext) string {
 	var expressionEvaluator = runContext.NewExpressionEvaluator(context.Background())
 	lines := strings.Split(logs, "\n")
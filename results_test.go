@@ -163,3 +163,58 @@ func TestJobResults_CalledWith(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, ok)
 }
+
+func Test_lookup_missing_job_results(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath(".github/workflows/example.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	_, err = results.LookupJob("missing")
+	assert.ErrorContains(t, err, "job 'missing' not found in results")
+	assert.ErrorContains(t, err, "cleanup")
+
+	_, err = results.RequireJob(t, "main").LookupStep("missing")
+	assert.ErrorContains(t, err, "step 'missing' not found in job 'main' results")
+
+	tb := requireFatal(func(tb testing.TB) { results.RequireJob(tb, "missing") })
+	assert.Contains(t, tb.fatal, "job 'missing' not found in results")
+
+	tb = requireFatal(func(tb testing.TB) { results.RequireJob(tb, "main").RequireStep(tb, "missing") })
+	assert.Contains(t, tb.fatal, "step 'missing' not found in job 'main' results")
+}
+
+func TestStepResults_OutputsAndEnv(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/step_env.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("build")
+	outputs := job.Step("outputs")
+	assert.Equal(t, map[string]string{"version": "1.2.3"}, outputs.Outputs())
+	assert.Equal(t, map[string]string{"BUILD_ENV": "production"}, outputs.EnvExports())
+	assert.Equal(t, []string{"/opt/tools/bin"}, outputs.PathAdditions())
+	assert.Equal(t, "version=1.2.3 env=production", job.Step("Report").Logs())
+}
+
+func TestStepResults_OutcomeAndConclusion(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/step_env.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	flaky := results.Job("build").Step("flaky")
+	assert.Equal(t, act_assert.Failure, flaky.Outcome())
+	assert.Equal(t, act_assert.Success, flaky.Conclusion())
+	assert.Equal(t, act_assert.Success, results.Job("build").Result())
+}
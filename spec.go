@@ -0,0 +1,280 @@
+package act_assert
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is a declarative test case for a workflow, read from a YAML or JSON file with LoadSpec.
+type Spec struct {
+	// Name describes the test case. Defaults to the path of the spec file.
+	Name string `yaml:"name"`
+	// Workflow is the path of the workflow file or directory under test. Like EventPath and Workdir, it is
+	// relative to the directory of the spec file.
+	Workflow string `yaml:"workflow"`
+	// Job restricts the plan to a single job.
+	Job       string            `yaml:"job"`
+	Event     string            `yaml:"event"`
+	EventPath string            `yaml:"event-path"`
+	Workdir   string            `yaml:"workdir"`
+	Inputs    map[string]string `yaml:"inputs"`
+	Env       map[string]string `yaml:"env"`
+	Secrets   map[string]string `yaml:"secrets"`
+	Vars      map[string]string `yaml:"vars"`
+	// DryRun predicts the results with ExecuteDryRun instead of running the workflow.
+	DryRun bool `yaml:"dry-run"`
+	// Jobs overrides the results and outputs of jobs and steps, keyed by job ID.
+	Jobs map[string]JobSpec `yaml:"jobs"`
+	// Expect contains the expected outcomes of jobs, keyed by job ID or name.
+	Expect map[string]JobExpectation `yaml:"expect"`
+
+	// dir is the directory of the spec file, which relative paths are resolved against.
+	dir string
+}
+
+// JobSpec overrides the behaviour of a job, see JobPlan.
+type JobSpec struct {
+	Result  Result              `yaml:"result"`
+	Outputs map[string]string   `yaml:"outputs"`
+	Skip    bool                `yaml:"skip"`
+	Image   string              `yaml:"image"`
+	Steps   map[string]StepSpec `yaml:"steps"`
+}
+
+// StepSpec overrides the behaviour of a step, see StepPlan.
+type StepSpec struct {
+	Result  Result            `yaml:"result"`
+	Outputs map[string]string `yaml:"outputs"`
+	Skip    bool              `yaml:"skip"`
+	Env     map[string]string `yaml:"env"`
+}
+
+// JobExpectation describes the expected outcome of a job.
+type JobExpectation struct {
+	Result      Result                     `yaml:"result"`
+	Outputs     map[string]string          `yaml:"outputs"`
+	LogContains []string                   `yaml:"log-contains"`
+	LogMatches  []string                   `yaml:"log-matches"`
+	Annotations []AnnotationExpectation    `yaml:"annotations"`
+	Steps       map[string]StepExpectation `yaml:"steps"`
+}
+
+// StepExpectation describes the expected outcome of a step.
+type StepExpectation struct {
+	Result      Result                  `yaml:"result"`
+	Outputs     map[string]string       `yaml:"outputs"`
+	LogContains []string                `yaml:"log-contains"`
+	LogMatches  []string                `yaml:"log-matches"`
+	Annotations []AnnotationExpectation `yaml:"annotations"`
+}
+
+// AnnotationExpectation matches an annotation with the given level whose message contains Message.
+type AnnotationExpectation struct {
+	Level   AnnotationLevel `yaml:"level"`
+	Message string          `yaml:"message"`
+}
+
+// LoadSpec reads a spec from a YAML or JSON file.
+func LoadSpec(path string) (*Spec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec Spec
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("reading spec %s: %w", path, err)
+	}
+	if spec.Workflow == "" {
+		return nil, fmt.Errorf("reading spec %s: workflow is required", path)
+	}
+	if spec.Name == "" {
+		spec.Name = path
+	}
+	spec.dir = filepath.Dir(path)
+	return &spec, nil
+}
+
+// path resolves a path of the spec relative to the directory of the spec file.
+func (s *Spec) path(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.dir, path)
+}
+
+// Run plans and executes the workflow of the spec and checks the expectations against the results. It
+// returns a description of each unmet expectation, or an error if the workflow could not be planned or run.
+func (s *Spec) Run() ([]string, error) {
+	act := New().WithWorkflowPath(s.path(s.Workflow))
+	if s.Job != "" {
+		act.WithJobName(s.Job)
+	}
+	if s.Event != "" {
+		act.WithEvent(s.Event)
+	}
+	if s.EventPath != "" {
+		act.WithEventPath(s.path(s.EventPath))
+	}
+	if s.Workdir != "" {
+		act.WithWorkdir(s.path(s.Workdir))
+	}
+	act.WithInputs(s.Inputs).WithSecrets(s.Secrets).WithVars(s.Vars)
+	env := make(map[GithubEnv]string, len(s.Env))
+	for k, v := range s.Env {
+		env[GithubEnv(k)] = v
+	}
+	act.WithEnvironment(env)
+
+	if _, err := act.Plan(); err != nil {
+		return nil, err
+	}
+	if err := s.apply(act); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if s.DryRun {
+		err = act.ExecuteDryRun()
+	} else {
+		err = act.Execute()
+	}
+	if err != nil && !IsWorkflowError(err) {
+		return nil, err
+	}
+	return s.Check(NewResults(*act)), nil
+}
+
+// apply applies the job and step overrides of the spec to the plan.
+func (s *Spec) apply(act *ActAssert) error {
+	for _, jobID := range slices.Sorted(maps.Keys(s.Jobs)) {
+		jobSpec := s.Jobs[jobID]
+		job, err := act.LookupJob(jobID)
+		if err != nil {
+			return err
+		}
+		if jobSpec.Skip {
+			job.Skip()
+		}
+		if jobSpec.Result != "" {
+			job.SetResult(jobSpec.Result)
+		}
+		for k, v := range jobSpec.Outputs {
+			job.SetOutput(k, v)
+		}
+		if jobSpec.Image != "" {
+			job.SetContainerImage(jobSpec.Image)
+		}
+		for _, stepName := range slices.Sorted(maps.Keys(jobSpec.Steps)) {
+			stepSpec := jobSpec.Steps[stepName]
+			step, err := job.LookupStep(stepName)
+			if err != nil {
+				return err
+			}
+			if stepSpec.Skip {
+				step.Skip(true)
+			}
+			if stepSpec.Result != "" {
+				step.SetResult(stepSpec.Result)
+			}
+			if len(stepSpec.Outputs) > 0 {
+				step.SetOutputs(stepSpec.Outputs)
+			}
+			if len(stepSpec.Env) > 0 {
+				step.SetEnv(stepSpec.Env)
+			}
+		}
+	}
+	return nil
+}
+
+// Check checks the expectations of the spec against results and returns a description of each unmet one.
+func (s *Spec) Check(results *Results) []string {
+	var failures []string
+	for _, jobName := range slices.Sorted(maps.Keys(s.Expect)) {
+		expected := s.Expect[jobName]
+		job, err := results.LookupJob(jobName)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		fail := func(format string, args ...any) {
+			failures = append(failures, fmt.Sprintf("job '%s': ", jobName)+fmt.Sprintf(format, args...))
+		}
+		checkResult(fail, expected.Result, job.Result())
+		checkOutputs(fail, expected.Outputs, job.Outputs())
+		checkLogs(fail, expected.LogContains, expected.LogMatches, job.Logs())
+		checkAnnotations(fail, expected.Annotations, job.Annotations())
+
+		for _, stepName := range slices.Sorted(maps.Keys(expected.Steps)) {
+			expectedStep := expected.Steps[stepName]
+			step, err := job.LookupStep(stepName)
+			if err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			failStep := func(format string, args ...any) {
+				fail("step '%s': %s", stepName, fmt.Sprintf(format, args...))
+			}
+			checkResult(failStep, expectedStep.Result, step.Result())
+			checkOutputs(failStep, expectedStep.Outputs, step.Outputs())
+			checkLogs(failStep, expectedStep.LogContains, expectedStep.LogMatches, step.Logs())
+			checkAnnotations(failStep, expectedStep.Annotations, step.Annotations())
+		}
+	}
+	return failures
+}
+
+func checkResult(fail func(string, ...any), expected, actual Result) {
+	if expected != "" && expected != actual {
+		fail("expected result '%s', got '%s'", expected, actual)
+	}
+}
+
+func checkOutputs(fail func(string, ...any), expected, actual map[string]string) {
+	for _, k := range slices.Sorted(maps.Keys(expected)) {
+		if v, ok := actual[k]; !ok || v != expected[k] {
+			fail("expected output '%s' to be '%s', got '%s'", k, expected[k], v)
+		}
+	}
+}
+
+func checkLogs(fail func(string, ...any), contains, matches []string, logs string) {
+	for _, s := range contains {
+		if !strings.Contains(logs, s) {
+			fail("expected logs to contain '%s'", s)
+		}
+	}
+	for _, pattern := range matches {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			fail("invalid log pattern '%s': %v", pattern, err)
+			continue
+		}
+		if !re.MatchString(logs) {
+			fail("expected logs to match '%s'", pattern)
+		}
+	}
+}
+
+func checkAnnotations(fail func(string, ...any), expected []AnnotationExpectation, annotations Annotations) {
+	for _, expectation := range expected {
+		found := slices.ContainsFunc(annotations, func(annotation Annotation) bool {
+			return (expectation.Level == "" || annotation.Level == expectation.Level) &&
+				strings.Contains(annotation.Message, expectation.Message)
+		})
+		if !found {
+			fail("expected %s annotation containing '%s'", cmp.Or(string(expectation.Level), "an"), expectation.Message)
+		}
+	}
+}
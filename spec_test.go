@@ -0,0 +1,33 @@
+package act_assert_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_run_spec(t *testing.T) {
+	spec, err := act_assert.LoadSpec("test/dry_run.act-test.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "Failed build skips deploy", spec.Name)
+
+	failures, err := spec.Run()
+	assert.NoError(t, err)
+	assert.Empty(t, failures)
+
+	spec.Expect["deploy"] = act_assert.JobExpectation{Result: act_assert.Success}
+	failures, err = spec.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"job 'deploy': expected result 'success', got 'skipped'"}, failures)
+}
+
+func Test_load_invalid_spec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.act-test.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("workflow: test/dry_run.yaml\nexpected: {}\n"), 0o644))
+
+	_, err := act_assert.LoadSpec(path)
+	assert.ErrorContains(t, err, "field expected not found")
+}
@@ -0,0 +1,263 @@
+package act_assert
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wd-hopkins/act/pkg/model"
+	"github.com/wd-hopkins/act/pkg/runner"
+)
+
+// stepSummariesDir is the directory in the job container where step summaries are captured. It is bind
+// mounted from a temporary directory on the host while the workflow is executed.
+const stepSummariesDir = "/tmp/act-assert/summaries"
+
+// CaptureStepSummaries captures the summary each step writes to $GITHUB_STEP_SUMMARY, see StepResults.Summary.
+// The summary files of the steps are redirected to a directory on the host, named after the step and the
+// matrix combination running it, so matrix values must not contain a slash.
+func (a *ActAssert) CaptureStepSummaries() *ActAssert {
+	a.captureStepSummaries = true
+	return a
+}
+
+// stepSummaries holds the captured summaries of the steps, keyed by the job and matrix combination running
+// them and by the index of the step in the job.
+type stepSummaries struct {
+	summary map[string]map[int]string
+}
+
+func summaryKey(jobID string, matrix map[string]any) string {
+	return jobID + "/" + matrixKey(matrix)
+}
+
+// collect reads the summaries of the steps of the job jobID from dir. The summary files are named after the
+// index of the step and the matrix combination running it.
+func (s *stepSummaries) collect(dir, jobID string) {
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".md")
+		if !ok {
+			continue
+		}
+		indexName, matrixJSON, _ := strings.Cut(name, "-")
+		index, err := strconv.Atoi(indexName)
+		if err != nil {
+			continue
+		}
+		var matrix map[string]any
+		_ = json.Unmarshal([]byte(matrixJSON), &matrix)
+		summary, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		key := summaryKey(jobID, matrix)
+		if s.summary[key] == nil {
+			s.summary[key] = map[int]string{}
+		}
+		s.summary[key][index] = string(summary)
+	}
+}
+
+// step returns the captured summary of the step with the given index, or false if step summaries were not
+// captured.
+func (s *stepSummaries) step(runContext *runner.RunContext, index int) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	return s.summary[summaryKey(runContext.Run.JobID, runContext.Matrix)][index], true
+}
+
+// applyStepSummaries redirects the step summaries of every job in the plan to a temporary directory on the
+// host. The returned function collects the summaries, removes the directory and restores the plan.
+func (a *ActAssert) applyStepSummaries() (func(), error) {
+	a.stepSummaries = nil
+	if !a.captureStepSummaries {
+		return func() {}, nil
+	}
+	dir, err := hostTempDir("act-assert-summaries-*")
+	if err != nil {
+		return nil, fmt.Errorf("step summaries: %w", err)
+	}
+	summaries := &stepSummaries{summary: map[string]map[int]string{}}
+	a.stepSummaries = summaries
+
+	var restore []func()
+	jobDirs := map[string]string{}
+	for _, stage := range a.plan.Stages {
+		for _, run := range stage.Runs {
+			jobDir := filepath.Join(dir, strconv.Itoa(len(restore)))
+			if err := os.Mkdir(jobDir, 0o777); err != nil {
+				_ = os.RemoveAll(dir)
+				return nil, fmt.Errorf("step summaries: %w", err)
+			}
+			_ = os.Chmod(jobDir, 0o777)
+			jobDirs[run.JobID] = jobDir
+			restore = append(restore, redirectStepSummaries(run, jobDir))
+		}
+	}
+	return func() {
+		for jobID, jobDir := range jobDirs {
+			summaries.collect(jobDir, jobID)
+		}
+		_ = os.RemoveAll(dir)
+		for _, f := range restore {
+			f()
+		}
+	}, nil
+}
+
+// redirectStepSummaries redirects the step summaries of the job run runs to files in jobDir, named after the
+// index of the step and, evaluated by the runner, the matrix combination. The returned function restores the
+// job.
+func redirectStepSummaries(run *model.Run, jobDir string) func() {
+	bindMounts := run.BindMounts
+	run.BindMounts = append(slices.Clone(bindMounts), jobDir+":"+stepSummariesDir)
+	envOverrides := map[*model.Step]map[string]string{}
+	for i, step := range run.Job().Steps {
+		envOverrides[step] = step.EnvOverrides
+		step.EnvOverrides = maps.Clone(step.EnvOverrides)
+		if step.EnvOverrides == nil {
+			step.EnvOverrides = map[string]string{}
+		}
+		step.EnvOverrides["GITHUB_STEP_SUMMARY"] = path.Join(stepSummariesDir,
+			fmt.Sprintf("%d-${{ toJSON(matrix) }}.md", i))
+	}
+	return func() {
+		run.BindMounts = bindMounts
+		for step, overrides := range envOverrides {
+			step.EnvOverrides = overrides
+		}
+	}
+}
+
+// Markdown is the structure of a Markdown document, such as a job summary.
+type Markdown struct {
+	Headings   []MarkdownHeading
+	Tables     []MarkdownTable
+	CodeBlocks []MarkdownCodeBlock
+}
+
+type MarkdownHeading struct {
+	Level int
+	Text  string
+}
+
+// MarkdownTable contains the cells of a table, row by row. The first row is the header row.
+type MarkdownTable [][]string
+
+type MarkdownCodeBlock struct {
+	Language string
+	Code     string
+}
+
+// Heading returns the first heading with the given text, or false if there is none.
+func (m Markdown) Heading(text string) (MarkdownHeading, bool) {
+	for _, heading := range m.Headings {
+		if heading.Text == text {
+			return heading, true
+		}
+	}
+	return MarkdownHeading{}, false
+}
+
+var (
+	markdownHeadingRegex = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	markdownFenceRegex   = regexp.MustCompile("^(```+|~~~+)\\s*([^`\\s]*)")
+	tableDelimiterRegex  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	htmlHeadingRegex     = regexp.MustCompile(`(?is)<h([1-6])[^>]*>(.*?)</h[1-6]>`)
+	htmlTableRegex       = regexp.MustCompile(`(?is)<table[^>]*>(.*?)</table>`)
+	htmlRowRegex         = regexp.MustCompile(`(?is)<tr[^>]*>(.*?)</tr>`)
+	htmlCellRegex        = regexp.MustCompile(`(?is)<t[hd][^>]*>(.*?)</t[hd]>`)
+	htmlCodeRegex        = regexp.MustCompile(`(?is)<pre[^>]*>\s*<code(?:\s+lang(?:uage)?="([^"]*)")?[^>]*>(.*?)</code>\s*</pre>`)
+	htmlTagRegex         = regexp.MustCompile(`(?s)<[^>]+>`)
+)
+
+// ParseMarkdown parses the headings, tables and fenced code blocks of a Markdown document. The HTML headings,
+// tables and code blocks written by the @actions/core summary API are parsed as well.
+func ParseMarkdown(markdown string) Markdown {
+	var doc Markdown
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if match := markdownFenceRegex.FindStringSubmatch(line); match != nil {
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), match[1]); i++ {
+				code = append(code, lines[i])
+			}
+			doc.CodeBlocks = append(doc.CodeBlocks, MarkdownCodeBlock{
+				Language: match[2],
+				Code:     strings.Join(code, "\n"),
+			})
+			continue
+		}
+		if match := markdownHeadingRegex.FindStringSubmatch(line); match != nil {
+			doc.Headings = append(doc.Headings, MarkdownHeading{Level: len(match[1]), Text: match[2]})
+			continue
+		}
+		if strings.Contains(line, "|") && i+1 < len(lines) && tableDelimiterRegex.MatchString(strings.TrimSpace(lines[i+1])) {
+			table := MarkdownTable{splitTableRow(line)}
+			for i += 2; i < len(lines) && strings.Contains(lines[i], "|"); i++ {
+				table = append(table, splitTableRow(strings.TrimSpace(lines[i])))
+			}
+			i--
+			doc.Tables = append(doc.Tables, table)
+			continue
+		}
+		for _, match := range htmlHeadingRegex.FindAllStringSubmatch(line, -1) {
+			level, _ := strconv.Atoi(match[1])
+			doc.Headings = append(doc.Headings, MarkdownHeading{Level: level, Text: htmlText(match[2])})
+		}
+	}
+
+	for _, tableMatch := range htmlTableRegex.FindAllStringSubmatch(markdown, -1) {
+		var table MarkdownTable
+		for _, rowMatch := range htmlRowRegex.FindAllStringSubmatch(tableMatch[1], -1) {
+			var row []string
+			for _, cellMatch := range htmlCellRegex.FindAllStringSubmatch(rowMatch[1], -1) {
+				row = append(row, htmlText(cellMatch[1]))
+			}
+			table = append(table, row)
+		}
+		doc.Tables = append(doc.Tables, table)
+	}
+	for _, match := range htmlCodeRegex.FindAllStringSubmatch(markdown, -1) {
+		doc.CodeBlocks = append(doc.CodeBlocks, MarkdownCodeBlock{
+			Language: match[1],
+			Code:     html.UnescapeString(match[2]),
+		})
+	}
+	return doc
+}
+
+// splitTableRow splits a Markdown table row into its cells, honouring escaped pipes.
+func splitTableRow(row string) []string {
+	row = strings.TrimPrefix(strings.TrimSuffix(row, "|"), "|")
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case row[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func htmlText(s string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTagRegex.ReplaceAllString(s, "")))
+}
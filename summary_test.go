@@ -0,0 +1,72 @@
+package act_assert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_parse_markdown(t *testing.T) {
+	markdown := act_assert.ParseMarkdown("# Report\n" +
+		"Some text\n" +
+		"| Suite | Passed |\n" +
+		"|:------|-------:|\n" +
+		"| unit \\| fast | 42 |\n" +
+		"\n" +
+		"```json\n" +
+		"{\"ok\": true}\n" +
+		"```\n" +
+		"<h3>Deployments</h3>\n" +
+		"<table><tr><th>Env</th><th>Status</th></tr><tr><td>prod</td><td><code>ok</code></td></tr></table>\n")
+
+	assert.Equal(t, []act_assert.MarkdownHeading{
+		{Level: 1, Text: "Report"},
+		{Level: 3, Text: "Deployments"},
+	}, markdown.Headings)
+	assert.Equal(t, []act_assert.MarkdownTable{
+		{{"Suite", "Passed"}, {"unit | fast", "42"}},
+		{{"Env", "Status"}, {"prod", "ok"}},
+	}, markdown.Tables)
+	assert.Equal(t, []act_assert.MarkdownCodeBlock{
+		{Language: "json", Code: `{"ok": true}`},
+	}, markdown.CodeBlocks)
+}
+
+func Test_capture_step_summaries(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/summary.yaml").
+		CaptureStepSummaries().
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("report")
+	summary := act_assert.ParseMarkdown(job.Step("Test report").Summary())
+	assert.Equal(t, []act_assert.MarkdownTable{{
+		{"Suite", "Passed", "Failed"},
+		{"unit", "42", "0"},
+		{"e2e", "7", "1"},
+	}}, summary.Tables)
+	assert.Equal(t, "## Coverage\n", job.Step("Coverage").Summary())
+	assert.Len(t, act_assert.ParseMarkdown(job.Summary()).Headings, 2)
+}
+
+func Test_capture_step_summaries_matrix(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/summary.yaml").
+		WithJobName("matrix_report").
+		CaptureStepSummaries().
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	for _, suite := range []string{"unit", "e2e"} {
+		job := results.MatrixJob("matrix_report").Combination(map[string]any{"suite": suite})
+		assert.Equal(t, "## "+suite+"\n", job.Step("0").Summary())
+	}
+}
@@ -0,0 +1,221 @@
+package act_assert
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wd-hopkins/act/pkg/runner"
+)
+
+// defaultTimeoutMinutes is the timeout of jobs and steps without timeout-minutes.
+const defaultTimeoutMinutes = 360
+
+// jobTiming returns the time the first event of the job was emitted and the time the job finished.
+// ok is false if no events were recorded for the job.
+func (r *eventRecorder) jobTiming(runContext *runner.RunContext) (start, end time.Time, ok bool) {
+	events := r.jobEvents(runContext)
+	if len(events) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	start = events[0].time
+	end = events[len(events)-1].time
+	for _, event := range events {
+		if _, finished := event.data["jobResult"]; finished {
+			end = event.time
+		}
+	}
+	return start, end, true
+}
+
+// stepTiming returns the time the first event of the step was emitted and the time the step finished.
+// ok is false if no events were recorded for the step.
+func (r *eventRecorder) stepTiming(runContext *runner.RunContext, stepID string) (start, end time.Time, ok bool) {
+	for _, event := range r.jobEvents(runContext) {
+		if event.stepID() != stepID {
+			continue
+		}
+		if !ok {
+			start, ok = event.time, true
+		}
+		end = event.time
+	}
+	return start, end, ok
+}
+
+// timing returns the time the job started and finished, as recorded from its logs. The timing of a job
+// calling a reusable workflow spans the jobs of the called workflow.
+func (j *JobResults) timing() (start, end time.Time, ok bool) {
+	if j.runContext.ChildContexts == nil {
+		return j.results.events.jobTiming(j.runContext)
+	}
+	for _, childContext := range *j.runContext.ChildContexts {
+		childStart, childEnd, childOk := j.results.events.jobTiming(childContext)
+		if !childOk {
+			continue
+		}
+		if !ok || childStart.Before(start) {
+			start = childStart
+		}
+		if !ok || childEnd.After(end) {
+			end = childEnd
+		}
+		ok = true
+	}
+	return start, end, ok
+}
+
+// StartTime returns the time the job started, as recorded from its logs. Zero if the job did not run, or
+// its results were predicted with ExecuteDryRun.
+func (j *JobResults) StartTime() time.Time {
+	start, _, _ := j.timing()
+	return start
+}
+
+// EndTime returns the time the job finished, as recorded from its logs. Zero if the job did not run.
+func (j *JobResults) EndTime() time.Time {
+	_, end, _ := j.timing()
+	return end
+}
+
+// Duration returns how long the job ran for. Zero if the job did not run.
+func (j *JobResults) Duration() time.Duration {
+	start, end, _ := j.timing()
+	return end.Sub(start)
+}
+
+// timing returns the time the step started and finished, as recorded from the job logs.
+func (s *StepResults) timing() (start, end time.Time, ok bool) {
+	return s.results.events.stepTiming(s.runContext, s.step.ID)
+}
+
+// StartTime returns the time the step started, as recorded from the job logs. Zero if the step did not run.
+func (s *StepResults) StartTime() time.Time {
+	start, _, _ := s.timing()
+	return start
+}
+
+// EndTime returns the time the step finished, as recorded from the job logs. Zero if the step did not run.
+func (s *StepResults) EndTime() time.Time {
+	_, end, _ := s.timing()
+	return end
+}
+
+// Duration returns how long the step ran for, including its pre and post stages. Zero if the step did not run.
+func (s *StepResults) Duration() time.Duration {
+	start, end, _ := s.timing()
+	return end.Sub(start)
+}
+
+// SimulateTimeout makes the job behave as if it ran past its timeout-minutes, 6 hours by default. Like on
+// GitHub, the job is cancelled while running its first step: the step is cancelled, only the following steps
+// whose condition checks always() or cancelled() run, and the job result is cancelled.
+func (j *JobPlan) SimulateTimeout() *JobPlan {
+	steps := j.jobRun.Job().Steps
+	if len(steps) == 0 {
+		return j.SetResult(Cancelled)
+	}
+	steps[0].Result = string(Cancelled)
+	return j
+}
+
+// SimulateTimeout makes the step behave as if it ran past its timeout-minutes. Like on GitHub, a step with
+// timeout-minutes fails and, unless continue-on-error is set, so does the job. A step without timeout-minutes
+// only times out with the job, in which case the step and the job are cancelled as with JobPlan.SimulateTimeout.
+func (s *StepPlan) SimulateTimeout() *StepPlan {
+	if strings.TrimSpace(s.step.TimeoutMinutes) == "" {
+		return s.SetResult(Cancelled)
+	}
+	return s.SetResult(Failure)
+}
+
+// Timeout returns the timeout-minutes of the job as a duration, defaulting to 6 hours.
+func (j *JobResults) Timeout() (time.Duration, error) {
+	return parseTimeoutMinutes(j.runContext.Run.Job().TimeoutMinutes)
+}
+
+// Timeout returns the timeout-minutes of the step as a duration, defaulting to 6 hours.
+func (s *StepResults) Timeout() (time.Duration, error) {
+	return parseTimeoutMinutes(s.step.TimeoutMinutes)
+}
+
+func parseTimeoutMinutes(timeoutMinutes string) (time.Duration, error) {
+	if strings.TrimSpace(timeoutMinutes) == "" {
+		return defaultTimeoutMinutes * time.Minute, nil
+	}
+	minutes, err := strconv.ParseFloat(strings.TrimSpace(timeoutMinutes), 64)
+	if err != nil {
+		return 0, fmt.Errorf("timeout-minutes '%s' is not a number", timeoutMinutes)
+	}
+	return time.Duration(minutes * float64(time.Minute)), nil
+}
+
+// AssertWithinTimeout asserts that the job, and each of its steps, finished within its timeout-minutes.
+func (j *JobResults) AssertWithinTimeout(t testing.TB) {
+	t.Helper()
+	timeout, err := j.Timeout()
+	if err != nil {
+		t.Fatalf("Job '%s': %v", j.JobName, err)
+	}
+	if duration := j.Duration(); duration > timeout {
+		t.Fatalf("Job '%s' ran for %s, exceeding its timeout of %s", j.JobName, duration, timeout)
+	}
+	for _, step := range j.Steps() {
+		step.AssertWithinTimeout(t)
+	}
+}
+
+// AssertWithinTimeout asserts that the step finished within its timeout-minutes.
+func (s *StepResults) AssertWithinTimeout(t testing.TB) {
+	t.Helper()
+	timeout, err := s.Timeout()
+	if err != nil {
+		t.Fatalf("Step '%s': %v", s.StepName, err)
+	}
+	if duration := s.Duration(); duration > timeout {
+		t.Fatalf("Step '%s' ran for %s, exceeding its timeout of %s", s.StepName, duration, timeout)
+	}
+}
+
+// AssertTimedOut asserts that the job ended the way a job running past its timeout-minutes does, e.g. after
+// JobPlan.SimulateTimeout: the job is cancelled.
+func (j *JobResults) AssertTimedOut(t testing.TB) {
+	t.Helper()
+	if result := j.Result(); result != Cancelled {
+		t.Fatalf("Job '%s' expected to time out with result '%s', got '%s'", j.JobName, Cancelled, result)
+	}
+}
+
+// AssertTimedOut asserts that the step ended the way a timed out step does, e.g. after
+// StepPlan.SimulateTimeout. A step with timeout-minutes has the outcome failure, and the conclusion success
+// only if continue-on-error evaluates to true. A step without timeout-minutes times out with its job and is
+// cancelled.
+func (s *StepResults) AssertTimedOut(t testing.TB) {
+	t.Helper()
+	if strings.TrimSpace(s.step.TimeoutMinutes) == "" {
+		if result := s.Result(); result != Cancelled {
+			t.Fatalf("Step '%s' expected to time out with result '%s', got '%s'", s.StepName, Cancelled, result)
+		}
+		return
+	}
+	if outcome := s.Outcome(); outcome != Failure {
+		t.Fatalf("Step '%s' expected to time out with outcome '%s', got '%s'", s.StepName, Failure, outcome)
+	}
+	expected := Failure
+	if s.continueOnError() {
+		expected = Success
+	}
+	if conclusion := s.Conclusion(); conclusion != expected {
+		t.Fatalf("Step '%s' expected to time out with conclusion '%s', got '%s'", s.StepName, expected, conclusion)
+	}
+}
+
+// continueOnError evaluates the continue-on-error setting of the step, which may be an expression.
+func (s *StepResults) continueOnError() bool {
+	ctx := context.Background()
+	evaluator := s.runContext.NewExpressionEvaluator(ctx)
+	return evaluator.Interpolate(ctx, strings.TrimSpace(s.step.RawContinueOnError)) == "true"
+}
@@ -0,0 +1,71 @@
+package act_assert_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_job_and_step_timing(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/timeouts.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	err = workflow.Execute()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	job := results.Job("build")
+	download := job.Step("download")
+	assert.False(t, job.StartTime().IsZero())
+	assert.False(t, job.EndTime().Before(download.EndTime()))
+	assert.GreaterOrEqual(t, download.Duration(), time.Second)
+	assert.GreaterOrEqual(t, job.Duration(), download.Duration())
+	job.AssertWithinTimeout(t)
+}
+
+func Test_simulate_timeout(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/timeouts.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("build").Step("download").SimulateTimeout()
+	workflow.Job("build").Step("build").SimulateTimeout()
+
+	_ = workflow.ExecuteDryRun()
+
+	results := act_assert.NewResults(*workflow)
+	results.Job("build").Step("download").AssertTimedOut(t)
+	results.Job("build").Step("build").AssertTimedOut(t)
+	assert.Equal(t, act_assert.Failure, results.Job("build").Result())
+
+	timeout, err := results.Job("build").Timeout()
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, timeout)
+}
+
+func Test_simulate_job_timeout(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/timeouts.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	workflow.Job("deploy").SimulateTimeout()
+
+	_ = workflow.ExecuteDryRun()
+
+	results := act_assert.NewResults(*workflow)
+	deploy := results.Job("deploy")
+	deploy.AssertTimedOut(t)
+	deploy.Step("deploy").AssertTimedOut(t)
+	assert.Equal(t, act_assert.Skipped, deploy.Step("notify").Result())
+	assert.Equal(t, act_assert.Success, deploy.Step("cleanup").Result())
+
+	timeout, err := deploy.Timeout()
+	assert.NoError(t, err)
+	assert.Equal(t, 360*time.Minute, timeout)
+}
@@ -0,0 +1,188 @@
+package act_assert
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/wd-hopkins/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// ReusableWorkflowStub describes the outcome of a mocked reusable workflow.
+type ReusableWorkflowStub struct {
+	// Result is the result of the calling job. Defaults to Success.
+	Result Result
+	// Outputs are the workflow outputs, available to the calling job's dependents as `needs.<job_id>.outputs`.
+	Outputs map[string]string
+}
+
+type reusableWorkflowMock struct {
+	uses string
+	stub *ReusableWorkflowStub
+	path string
+}
+
+// MockReusableWorkflow replaces the reusable workflow called by every job using uses with a workflow producing
+// the result and outputs of stub. uses may end in `@*` to match any ref, e.g.
+// org/repo/.github/workflows/deploy.yml@*, so that remote workflows can be tested offline.
+func (a *ActAssert) MockReusableWorkflow(uses string, stub ReusableWorkflowStub) *ActAssert {
+	a.reusableWorkflowMocks = append(a.reusableWorkflowMocks, reusableWorkflowMock{uses: uses, stub: &stub})
+	return a
+}
+
+// MockReusableWorkflowWithPath replaces the reusable workflow called by every job using uses with the local
+// workflow file at path, relative to the working directory.
+func (a *ActAssert) MockReusableWorkflowWithPath(uses, path string) *ActAssert {
+	a.reusableWorkflowMocks = append(a.reusableWorkflowMocks, reusableWorkflowMock{uses: uses, path: path})
+	return a
+}
+
+// cancelledByWorkflowMock reports whether job calls a reusable workflow mocked with a cancelled stub.
+func (a *ActAssert) cancelledByWorkflowMock(job *model.Job) bool {
+	mock := a.findReusableWorkflowMock(job.Uses)
+	return mock != nil && mock.stub != nil && mock.stub.Result == Cancelled
+}
+
+func (a *ActAssert) findReusableWorkflowMock(uses string) *reusableWorkflowMock {
+	if uses == "" {
+		return nil
+	}
+	for i := len(a.reusableWorkflowMocks) - 1; i >= 0; i-- {
+		if matchesUses(a.reusableWorkflowMocks[i].uses, uses) {
+			return &a.reusableWorkflowMocks[i]
+		}
+	}
+	return nil
+}
+
+// applyReusableWorkflowMocks points the jobs calling mocked reusable workflows at local workflows, writing the
+// workflows of stubs to a temporary directory in the working directory, where the runner resolves local
+// workflows. It returns a function restoring the plan.
+func (a *ActAssert) applyReusableWorkflowMocks() (func(), error) {
+	if len(a.reusableWorkflowMocks) == 0 {
+		return func() {}, nil
+	}
+	originalUses := map[*model.Job]string{}
+	var stubDir string
+	restore := func() {
+		for job, uses := range originalUses {
+			job.Uses = uses
+		}
+		if stubDir != "" {
+			_ = os.RemoveAll(stubDir)
+		}
+	}
+
+	for _, stage := range a.plan.Stages {
+		for _, run := range stage.Runs {
+			job := run.Job()
+			mock := a.findReusableWorkflowMock(job.Uses)
+			if mock == nil {
+				continue
+			}
+			path := mock.path
+			if mock.stub != nil {
+				if stubDir == "" {
+					dir, err := os.MkdirTemp(a.workdir, ".act-assert-workflows-")
+					if err != nil {
+						restore()
+						return nil, err
+					}
+					stubDir = dir
+				}
+				path = filepath.Join(stubDir, fmt.Sprintf("%s.yml", run.JobID))
+				if err := writeStubWorkflow(path, job, *mock.stub); err != nil {
+					restore()
+					return nil, err
+				}
+			}
+			originalUses[job] = job.Uses
+			job.Uses = localWorkflowPath(path, a.workdir)
+		}
+	}
+	return restore, nil
+}
+
+// localWorkflowPath returns path as a local reusable workflow reference relative to workdir.
+func localWorkflowPath(path, workdir string) string {
+	uses := localActionPath(path, workdir)
+	if strings.HasPrefix(uses, "../") {
+		uses = "./" + uses
+	}
+	return uses
+}
+
+// writeStubWorkflow writes a reusable workflow to path, accepting the inputs job calls it with and producing
+// the result and outputs of stub.
+func writeStubWorkflow(path string, job *model.Job, stub ReusableWorkflowStub) error {
+	inputs := map[string]any{}
+	for k := range job.With {
+		inputs[k] = map[string]any{"required": false}
+	}
+	outputs := map[string]any{}
+	stubJob := map[string]any{"runs-on": "ubuntu-latest"}
+	if len(stub.Outputs) > 0 {
+		jobOutputs := map[string]string{}
+		for k, v := range stub.Outputs {
+			outputs[k] = map[string]string{"value": fmt.Sprintf("${{ jobs.stub.outputs.%s }}", k)}
+			jobOutputs[k] = v
+		}
+		stubJob["outputs"] = jobOutputs
+	}
+
+	script := "echo 'Mocked reusable workflow'"
+	switch stub.Result {
+	case Failure:
+		script += "\nexit 1"
+	case Skipped:
+		stubJob["if"] = "false"
+	case Cancelled:
+		// The runner cannot cancel the workflow, which succeeds. applyCancellations reports the calling job
+		// as cancelled, as ExecuteDryRun does.
+	}
+	stubJob["steps"] = []map[string]string{{"name": "Stub", "run": script}}
+
+	workflow := map[string]any{
+		"name": "Mocked " + job.Uses,
+		"on": map[string]any{
+			"workflow_call": map[string]any{"inputs": inputs, "outputs": outputs},
+		},
+		"jobs": map[string]any{"stub": stubJob},
+	}
+	b, err := yaml.Marshal(workflow)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// dryRunReusableWorkflow predicts the result and outputs of a job calling a reusable workflow, using its stub
+// if it is mocked with MockReusableWorkflow.
+func (a *ActAssert) dryRunReusableWorkflow(job *model.Job, evaluator *expressionEvaluator) (map[string]string, error) {
+	with := make(map[string]string, len(job.With))
+	for _, k := range slices.Sorted(maps.Keys(job.With)) {
+		value, ok := job.With[k].(string)
+		if !ok {
+			with[k] = formatValue(job.With[k])
+			continue
+		}
+		evaluated, err := evaluator.interpolate(value)
+		if err != nil {
+			return nil, err
+		}
+		with[k] = evaluated
+	}
+
+	job.Result = string(Success)
+	if mock := a.findReusableWorkflowMock(job.Uses); mock != nil && mock.stub != nil {
+		if mock.stub.Result != "" {
+			job.Result = string(mock.stub.Result)
+		}
+		job.Outputs = maps.Clone(mock.stub.Outputs)
+	}
+	return with, nil
+}
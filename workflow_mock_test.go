@@ -0,0 +1,95 @@
+package act_assert_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	act_assert "github.com/wd-hopkins/act-assert"
+)
+
+func Test_mock_reusable_workflow(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/remote_caller.yaml").
+		MockReusableWorkflow("my-org/workflows/.github/workflows/deploy.yml@*", act_assert.ReusableWorkflowStub{
+			Outputs: map[string]string{"url": "https://staging.example.com"},
+		}).
+		Plan()
+	assert.NoError(t, err)
+
+	err = workflow.Execute()
+	assert.NoError(t, err)
+
+	results := act_assert.NewResults(*workflow)
+	deploy := results.Job("deploy")
+	assert.Equal(t, act_assert.Success, deploy.Result())
+	assert.Equal(t, "https://staging.example.com", deploy.Outputs()["url"])
+	ok, err := deploy.WasCalledWith(map[string]string{"environment": "staging", "version": "main"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, results.Job("notify").Logs(), "deployed to https://staging.example.com")
+}
+
+func Test_mock_reusable_workflow_dry_run(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/remote_caller.yaml").
+		MockReusableWorkflow("my-org/workflows/.github/workflows/deploy.yml@v1", act_assert.ReusableWorkflowStub{
+			Result: act_assert.Failure,
+		}).
+		Plan()
+	assert.NoError(t, err)
+
+	err = workflow.ExecuteDryRun()
+	assert.True(t, act_assert.IsWorkflowError(err))
+
+	results := act_assert.NewResults(*workflow)
+	assert.Equal(t, act_assert.Failure, results.Job("deploy").Result())
+	ok, err := results.Job("deploy").WasCalledWith(map[string]string{"environment": "staging", "version": "main"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, act_assert.Skipped, results.Job("notify").Result())
+}
+
+func Test_mock_reusable_workflow_cancelled(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/remote_caller.yaml").
+		MockReusableWorkflow("my-org/workflows/.github/workflows/deploy.yml@*", act_assert.ReusableWorkflowStub{
+			Result: act_assert.Cancelled,
+		}).
+		Plan()
+	assert.NoError(t, err)
+
+	// Execute agrees with the dry run
+	for name, execute := range map[string]func() error{
+		"execute": workflow.Execute,
+		"dry run": workflow.ExecuteDryRun,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_ = execute()
+
+			results := act_assert.NewResults(*workflow)
+			assert.Equal(t, act_assert.Cancelled, results.Job("deploy").Result())
+			assert.Equal(t, act_assert.Skipped, results.Job("notify").Result())
+			assert.Equal(t, act_assert.Success, results.Job("rollback").Result())
+		})
+	}
+
+	// The stub workflows are removed from the working directory
+	stubs, err := filepath.Glob(".act-assert-workflows-*")
+	assert.NoError(t, err)
+	assert.Empty(t, stubs)
+}
+
+func Test_mock_reusable_workflow_with_path(t *testing.T) {
+	workflow, err := act_assert.New().
+		WithWorkflowPath("test/remote_caller.yaml").
+		MockReusableWorkflowWithPath("my-org/workflows/.github/workflows/deploy.yml@*", "test/callee.yaml").
+		Plan()
+	assert.NoError(t, err)
+
+	_ = workflow.Execute()
+
+	results := act_assert.NewResults(*workflow)
+	assert.Equal(t, act_assert.Success, results.Job("job_1").Result())
+	assert.Equal(t, act_assert.Success, results.Job("reusable_job_2").Result())
+}